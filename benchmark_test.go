@@ -0,0 +1,127 @@
+package serrors
+
+import (
+	"errors"
+	"io"
+	"strconv"
+	"testing"
+)
+
+// frames are resolved lazily, so New, Wrap and Lift only pay for runtime.Callers.
+// *_Resolved benchmarks show the cost when the stack trace is actually used.
+
+func BenchmarkNewStackTrace(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = NewStackTrace(0, MaxStackTraceDepth)
+	}
+}
+
+func BenchmarkNew(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = New("benchmark error")
+	}
+}
+
+func BenchmarkNew_Resolved(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = New("benchmark error").(SError).StackTrace()
+	}
+}
+
+func BenchmarkWrap(b *testing.B) {
+	stdErr := errors.New("benchmark error")
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = Wrap(stdErr, "wrapped")
+	}
+}
+
+func BenchmarkWrap_Resolved(b *testing.B) {
+	stdErr := errors.New("benchmark error")
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = Wrap(stdErr, "wrapped").(SError).StackTrace()
+	}
+}
+
+func BenchmarkWrap_HasStackTrace(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		err := New("benchmark error")
+		b.StartTimer()
+		_ = Wrap(err, "wrapped")
+	}
+}
+
+func BenchmarkLift(b *testing.B) {
+	stdErr := errors.New("benchmark error")
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = Lift(stdErr)
+	}
+}
+
+func BenchmarkLift_Resolved(b *testing.B) {
+	stdErr := errors.New("benchmark error")
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = Lift(stdErr).(SError).StackTrace()
+	}
+}
+
+func BenchmarkLift_HasStackTrace(b *testing.B) {
+	err := New("benchmark error")
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = Lift(err)
+	}
+}
+
+func newBenchmarkJsonError() *StructuredError {
+	fe := NewRawStructuredError(errors.New("benchmark error"))
+	_ = fe.SetRequestID("req-123")
+	for i := 0; i < 50; i++ {
+		_ = fe.AddTagString("string_"+strconv.Itoa(i), "value with \"quotes\"")
+		_ = fe.AddTagInt("int_"+strconv.Itoa(i), i)
+	}
+	_ = fe.WithStackTrace()
+	for i := 0; i < 20; i++ {
+		sub := NewRawStructuredError(errors.New("sub error " + strconv.Itoa(i)))
+		_ = sub.WithStackTrace()
+		_ = fe.AddSubError(sub)
+	}
+	_ = fe.StackTrace()
+	return fe
+}
+
+func BenchmarkStructuredError_JsonString(b *testing.B) {
+	fe := newBenchmarkJsonError()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = fe.JsonString()
+	}
+}
+
+func BenchmarkStructuredError_WriteJSON(b *testing.B) {
+	fe := newBenchmarkJsonError()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = fe.WriteJSON(io.Discard)
+	}
+}
+
+func BenchmarkStructuredError_AppendJSON(b *testing.B) {
+	fe := newBenchmarkJsonError()
+	buf := make([]byte, 0, 64*1024)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		buf = fe.AppendJSON(buf[:0])
+	}
+}
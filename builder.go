@@ -1,6 +1,9 @@
 package serrors
 
-import "time"
+import (
+	"context"
+	"time"
+)
 
 func Builder(err error) *StructuredErrorBuilder {
 	if err == nil {
@@ -59,6 +62,27 @@ func (w *StructuredErrorBuilder) When(t time.Time) *StructuredErrorBuilder {
 	return w
 }
 
+// Context sets request id and tags in ctx like WithContext()
+func (w *StructuredErrorBuilder) Context(ctx context.Context) *StructuredErrorBuilder {
+	if w.err == nil {
+		return w
+	}
+	applyContext(ctx, w.err)
+	return w
+}
+
+// PublicMessage sets the message safe to show to users
+// it is ignored if the error does not implement SetPublicMessage()
+func (w *StructuredErrorBuilder) PublicMessage(message string) *StructuredErrorBuilder {
+	if w.err == nil {
+		return w
+	}
+	if pm, ok := w.err.(interface{ SetPublicMessage(string) SError }); ok {
+		_ = pm.SetPublicMessage(message)
+	}
+	return w
+}
+
 func (w *StructuredErrorBuilder) AddTagSafe(key string, value TagValue) *StructuredErrorBuilder {
 	if w.err == nil {
 		return w
@@ -67,6 +91,15 @@ func (w *StructuredErrorBuilder) AddTagSafe(key string, value TagValue) *Structu
 	return w
 }
 
+// AddTag converts value into TagValue by ToTagValue() and adds it
+func (w *StructuredErrorBuilder) AddTag(key string, value any) *StructuredErrorBuilder {
+	if w.err == nil {
+		return w
+	}
+	_ = w.err.AddTagSafe(key, ToTagValue(value))
+	return w
+}
+
 func (w *StructuredErrorBuilder) AddTagString(key string, value string) *StructuredErrorBuilder {
 	if w.err == nil {
 		return w
@@ -99,6 +132,31 @@ func (w *StructuredErrorBuilder) AddTagBool(key string, value bool) *StructuredE
 	return w
 }
 
+func (w *StructuredErrorBuilder) AddTagTime(key string, value time.Time) *StructuredErrorBuilder {
+	if w.err == nil {
+		return w
+	}
+	_ = w.err.AddTagSafe(key, TimeTagValue{Time: value})
+	return w
+}
+
+func (w *StructuredErrorBuilder) AddTagDuration(key string, value time.Duration) *StructuredErrorBuilder {
+	if w.err == nil {
+		return w
+	}
+	_ = w.err.AddTagSafe(key, DurationTagValue(value))
+	return w
+}
+
+// AddTagSensitive adds value as SecretTagValue, which is printed as RedactedStr
+func (w *StructuredErrorBuilder) AddTagSensitive(key string, value any) *StructuredErrorBuilder {
+	if w.err == nil {
+		return w
+	}
+	_ = w.err.AddTagSafe(key, SecretTagValue{Value: ToTagValue(value)})
+	return w
+}
+
 func (w *StructuredErrorBuilder) DeleteTag(key string) *StructuredErrorBuilder {
 	if w.err == nil {
 		return w
@@ -513,3 +513,86 @@ func TestStructuredErrorBuilder_DeleteTag(t *testing.T) {
 		})
 	}
 }
+
+func TestStructuredErrorBuilder_AddTag(t *testing.T) {
+	testCases := []struct {
+		label    string
+		wrapper  *StructuredErrorBuilder
+		key      string
+		value    any
+		expected error
+	}{
+		{
+			label:   "int64",
+			wrapper: Builder(errStd),
+			key:     "key1",
+			value:   int64(10),
+			expected: NewRawStructuredError(errStd).
+				AddTagSafe("key1", IntTagValue(10)),
+		},
+		{
+			label:   "map",
+			wrapper: Builder(errStd),
+			key:     "key1",
+			value:   map[string]bool{"ok": true},
+			expected: NewRawStructuredError(errStd).
+				AddTagSafe("key1", newTestObjectTagValue("ok", BoolTagValue(true))),
+		},
+		{
+			label:    "nil error",
+			wrapper:  Builder(nil),
+			key:      "key1",
+			value:    1,
+			expected: nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.label, func(t *testing.T) {
+			err := tc.wrapper.AddTag(tc.key, tc.value).Build()
+			if tc.expected == nil {
+				if err != nil {
+					t.Errorf("expected nil, got %v", err)
+				}
+				return
+			}
+			if !reflect.DeepEqual(tc.expected, err) {
+				t.Errorf("expected %v, got %v", tc.expected, err)
+			}
+		})
+	}
+}
+
+func TestStructuredErrorBuilder_AddTagTime(t *testing.T) {
+	tm := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	err := Builder(errStd).AddTagTime("deadline", tm).Build()
+	expected := NewRawStructuredError(errStd).AddTagSafe("deadline", TimeTagValue{Time: tm})
+	if !reflect.DeepEqual(expected, err) {
+		t.Errorf("expected %v, got %v", expected, err)
+	}
+	if Builder(nil).AddTagTime("deadline", tm).Build() != nil {
+		t.Errorf("expected nil for nil error")
+	}
+}
+
+func TestStructuredErrorBuilder_AddTagDuration(t *testing.T) {
+	err := Builder(errStd).AddTagDuration("timeout", 3*time.Second).Build()
+	expected := NewRawStructuredError(errStd).AddTagSafe("timeout", DurationTagValue(3*time.Second))
+	if !reflect.DeepEqual(expected, err) {
+		t.Errorf("expected %v, got %v", expected, err)
+	}
+	if Builder(nil).AddTagDuration("timeout", 3*time.Second).Build() != nil {
+		t.Errorf("expected nil for nil error")
+	}
+}
+
+func TestStructuredErrorBuilder_AddTagSensitive(t *testing.T) {
+	err := Builder(errStd).AddTagSensitive("email", "alice@example.com").Build()
+	expected := NewRawStructuredError(errStd).AddTagSafe("email", SecretTagValue{Value: StringTagValue("alice@example.com")})
+	if !reflect.DeepEqual(expected, err) {
+		t.Errorf("expected %v, got %v", expected, err)
+	}
+	if Builder(nil).AddTagSensitive("email", "alice@example.com").Build() != nil {
+		t.Errorf("expected nil for nil error")
+	}
+}
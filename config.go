@@ -5,3 +5,9 @@ func GetMaxDepthStackTrace() int {
 }
 
 const MaxStackTraceDepth int = 32
+
+// MaxTagValueDepth is the maximum nesting depth of ObjectTagValue and ArrayTagValue in output
+// deeper values are replaced with TruncatedTagValueStr
+const MaxTagValueDepth int = 8
+
+const TruncatedTagValueStr string = "[truncated]"
@@ -0,0 +1,244 @@
+package serrors
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+)
+
+type contextKey int
+
+const (
+	requestIDContextKey contextKey = iota
+	tagsContextKey
+)
+
+// tag keys of trace and span ids added by TraceContextExtractor
+const (
+	TraceIDTagKey string = "trace_id"
+	SpanIDTagKey  string = "span_id"
+)
+
+// error types set by FromContext()
+// they are children of "context", so IsTypeOrChild(err, "context") matches both.
+const (
+	TypeCanceled         ErrorType = "context.canceled"
+	TypeDeadlineExceeded ErrorType = "context.deadline_exceeded"
+)
+
+// tag keys added by FromContext()
+const (
+	ContextCauseTagKey     string = "context_cause"
+	ContextDeadlineTagKey  string = "context_deadline"
+	ContextRemainingTagKey string = "context_remaining"
+	ContextOverrunTagKey   string = "context_overrun"
+)
+
+// ContextWithRequestID returns a copy of ctx carrying requestID
+// WithContext(), Builder.Context() and NewCtx() set it to errors.
+func ContextWithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDContextKey, requestID)
+}
+
+// RequestIDFromContext returns the request id set by ContextWithRequestID()
+func RequestIDFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	requestID, _ := ctx.Value(requestIDContextKey).(string)
+	return requestID
+}
+
+// ContextWithTags returns a copy of ctx carrying tags merged with tags already in ctx
+// if the same key exists, the value of tags wins.
+func ContextWithTags(ctx context.Context, tags Tags) context.Context {
+	merged := TagsFromContext(ctx)
+	for _, tag := range tags.tags {
+		merged.SetValueSafe(tag.Key, tag.Value)
+	}
+	return context.WithValue(ctx, tagsContextKey, merged)
+}
+
+// TagsFromContext returns a copy of tags set by ContextWithTags()
+func TagsFromContext(ctx context.Context) Tags {
+	copied := NewTags()
+	if ctx == nil {
+		return copied
+	}
+	tags, ok := ctx.Value(tagsContextKey).(Tags)
+	if !ok {
+		return copied
+	}
+	for _, tag := range tags.tags {
+		copied.SetValueSafe(tag.Key, tag.Value)
+	}
+	return copied
+}
+
+// ContextExtractor sets values in ctx to err
+// Use it for keys of your own or of other libraries, e.g. trace ids of OpenTelemetry.
+type ContextExtractor interface {
+	Extract(ctx context.Context, err SError)
+}
+
+type ContextExtractorFunc func(ctx context.Context, err SError)
+
+func (f ContextExtractorFunc) Extract(ctx context.Context, err SError) {
+	f(ctx, err)
+}
+
+type contextExtractorHolder struct {
+	extractor ContextExtractor
+}
+
+var defaultContextExtractor atomic.Value
+
+// SetDefaultContextExtractor sets ContextExtractor called after request id and tags are set from context
+// if e is nil, only request id and tags are set.
+func SetDefaultContextExtractor(e ContextExtractor) {
+	defaultContextExtractor.Store(contextExtractorHolder{extractor: e})
+}
+
+func DefaultContextExtractor() ContextExtractor {
+	holder, _ := defaultContextExtractor.Load().(contextExtractorHolder)
+	return holder.extractor
+}
+
+// ContextExtractors chains extractors in order
+func ContextExtractors(extractors ...ContextExtractor) ContextExtractor {
+	return ContextExtractorFunc(func(ctx context.Context, err SError) {
+		for _, e := range extractors {
+			if e != nil {
+				e.Extract(ctx, err)
+			}
+		}
+	})
+}
+
+// TraceContextExtractor adds trace and span ids returned by ids as tags TraceIDTagKey and SpanIDTagKey
+// empty ids are not added. e.g. with OpenTelemetry:
+//
+//	serrors.SetDefaultContextExtractor(serrors.TraceContextExtractor(func(ctx context.Context) (string, string) {
+//		sc := trace.SpanContextFromContext(ctx)
+//		if !sc.IsValid() {
+//			return "", ""
+//		}
+//		return sc.TraceID().String(), sc.SpanID().String()
+//	}))
+func TraceContextExtractor(ids func(ctx context.Context) (traceID string, spanID string)) ContextExtractor {
+	return ContextExtractorFunc(func(ctx context.Context, err SError) {
+		traceID, spanID := ids(ctx)
+		if traceID != "" {
+			_ = err.AddTagSafe(TraceIDTagKey, StringTagValue(traceID))
+		}
+		if spanID != "" {
+			_ = err.AddTagSafe(SpanIDTagKey, StringTagValue(spanID))
+		}
+	})
+}
+
+// applyContext sets request id, tags and values extracted by DefaultContextExtractor() from ctx to err
+// request id and tags already set to err are kept.
+func applyContext(ctx context.Context, err SError) {
+	if ctx == nil || err == nil {
+		return
+	}
+	if requestID := RequestIDFromContext(ctx); requestID != "" && err.RequestID() == "" {
+		_ = err.SetRequestID(requestID)
+	}
+	tagged, canCheck := err.(interface{ hasTag(key string) bool })
+	for _, tag := range TagsFromContext(ctx).tags {
+		if canCheck && tagged.hasTag(tag.Key) {
+			continue
+		}
+		_ = err.AddTagSafe(tag.Key, tag.Value)
+	}
+	if e := DefaultContextExtractor(); e != nil {
+		e.Extract(ctx, err)
+	}
+}
+
+// WithContext sets request id and tags in ctx, and values extracted by DefaultContextExtractor()
+// request id and tags already set to the error are kept.
+func WithContext(ctx context.Context) WithFunc {
+	return func(err error) error {
+		fe := ToStructuredError(err)
+		if fe == nil {
+			return nil
+		}
+		applyContext(ctx, fe)
+		return fe
+	}
+}
+
+// CopyWithContext is similar to WithContext() but sets values in ctx to a copy of err without changing err
+// use it for errors which can be shared by goroutines, such as package level errors.
+// if err is not *StructuredError, it is wrapped by a new StructuredError. if err is nil, it returns nil.
+func CopyWithContext(ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+	fe, ok := err.(*StructuredError)
+	if ok {
+		fe = fe.clone()
+	} else {
+		fe = NewRawStructuredError(err)
+	}
+	applyContext(ctx, fe)
+	return fe
+}
+
+// NewCtx is similar to New() but sets values in ctx like WithContext()
+func NewCtx(ctx context.Context, text string) error {
+	fe := NewRawStructuredError(errors.New(text))
+	// set stack trace starting from caller of NewCtx
+	_ = fe.SetStackTraceWithSkipMaxDepth(2, MaxStackTraceDepth)
+	applyContext(ctx, fe)
+	return fe
+}
+
+// FromContext lifts err like Lift() and records details of ctx
+//   - type is set to TypeCanceled or TypeDeadlineExceeded if err wraps context.Canceled or
+//     context.DeadlineExceeded, and err has no type yet
+//   - context.Cause(ctx) is added as ContextCauseTagKey if ctx is done
+//   - the deadline is added as ContextDeadlineTagKey, and time remaining or overrun
+//     as ContextRemainingTagKey or ContextOverrunTagKey if ctx has a deadline
+//
+// if err is nil, it returns nil.
+func FromContext(ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+	fe := ToStructured(err)
+	if !hasStackTrace(fe) {
+		_ = fe.SetStackTraceWithSkipMaxDepth(2, MaxStackTraceDepth) // skip 2 to start at caller of FromContext
+	}
+	if ctx == nil {
+		return fe
+	}
+
+	if fe.Type() == ErrorTypeNone {
+		switch {
+		case errors.Is(err, context.DeadlineExceeded):
+			_ = fe.SetType(TypeDeadlineExceeded)
+		case errors.Is(err, context.Canceled):
+			_ = fe.SetType(TypeCanceled)
+		}
+	}
+	if ctx.Err() != nil {
+		if cause := context.Cause(ctx); cause != nil {
+			_ = fe.AddTagSafe(ContextCauseTagKey, StringTagValue(cause.Error()))
+		}
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = fe.AddTagSafe(ContextDeadlineTagKey, TimeTagValue{Time: deadline})
+		remaining := time.Until(deadline)
+		if remaining >= 0 {
+			_ = fe.AddTagSafe(ContextRemainingTagKey, DurationTagValue(remaining))
+		} else {
+			_ = fe.AddTagSafe(ContextOverrunTagKey, DurationTagValue(-remaining))
+		}
+	}
+	return fe
+}
@@ -0,0 +1,283 @@
+package serrors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func newTestContext() context.Context {
+	ctx := ContextWithRequestID(context.Background(), "req-ctx")
+	tags := NewTags()
+	tags.SetValue("user_id", 42)
+	tags.SetValue("route", "/users")
+	ctx = ContextWithTags(ctx, tags)
+	override := NewTags()
+	override.SetValue("route", "/users/42")
+	return ContextWithTags(ctx, override)
+}
+
+func TestContextWithTags(t *testing.T) {
+	ctx := newTestContext()
+	got := TagsFromContext(ctx)
+	expected := `{"user_id":42,"route":"/users/42"}`
+	if got.JsonValueString() != expected {
+		t.Errorf("expected %s, got %s", expected, got.JsonValueString())
+	}
+
+	// modifying the returned tags does not affect the context
+	got.SetValue("extra", true)
+	if again := TagsFromContext(ctx); again.JsonValueString() != expected {
+		t.Errorf("expected tags in context not to be modified, got %s", again.JsonValueString())
+	}
+
+	if got := TagsFromContext(context.Background()); got.JsonValueString() != `{}` {
+		t.Errorf("expected empty tags, got %s", got.JsonValueString())
+	}
+}
+
+func TestRequestIDFromContext(t *testing.T) {
+	if got := RequestIDFromContext(newTestContext()); got != "req-ctx" {
+		t.Errorf("expected req-ctx, got %s", got)
+	}
+	if got := RequestIDFromContext(context.Background()); got != "" {
+		t.Errorf("expected empty request id, got %s", got)
+	}
+}
+
+func TestWithContext(t *testing.T) {
+	testCases := []struct {
+		label       string
+		err         error
+		ctx         context.Context
+		expectedID  string
+		expectedTag string
+	}{
+		{
+			label:       "standard error",
+			err:         errors.New("boom"),
+			ctx:         newTestContext(),
+			expectedID:  "req-ctx",
+			expectedTag: `{"user_id":42,"route":"/users/42"}`,
+		},
+		{
+			label: "request id and tags set to the error are kept",
+			err: With(errors.New("boom"),
+				WithRequestID("req-err"),
+				WithTag("route", "/admin"),
+			),
+			ctx:         newTestContext(),
+			expectedID:  "req-err",
+			expectedTag: `{"route":"/admin","user_id":42}`,
+		},
+		{
+			label:       "empty context",
+			err:         errors.New("boom"),
+			ctx:         context.Background(),
+			expectedID:  "",
+			expectedTag: `{}`,
+		},
+		{
+			label:       "nil context",
+			err:         errors.New("boom"),
+			ctx:         nil,
+			expectedID:  "",
+			expectedTag: `{}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.label, func(t *testing.T) {
+			got := With(tc.err, WithContext(tc.ctx)).(*StructuredError)
+			if got.RequestID() != tc.expectedID {
+				t.Errorf("expected request id %q, got %q", tc.expectedID, got.RequestID())
+			}
+			if got.tags.JsonValueString() != tc.expectedTag {
+				t.Errorf("expected tags %s, got %s", tc.expectedTag, got.tags.JsonValueString())
+			}
+
+			built := Builder(tc.err).Context(tc.ctx).Build().(*StructuredError)
+			if built.RequestID() != tc.expectedID {
+				t.Errorf("Builder: expected request id %q, got %q", tc.expectedID, built.RequestID())
+			}
+		})
+	}
+}
+
+func TestCopyWithContext(t *testing.T) {
+	sentinel := With(errors.New("not found"), WithType("notFound"), WithTag("route", "/admin"))
+
+	got := CopyWithContext(newTestContext(), sentinel).(*StructuredError)
+	if got.RequestID() != "req-ctx" {
+		t.Errorf("expected request id req-ctx, got %q", got.RequestID())
+	}
+	if got.tags.JsonValueString() != `{"route":"/admin","user_id":42}` {
+		t.Errorf("unexpected tags %s", got.tags.JsonValueString())
+	}
+	if !errors.Is(got, sentinel) || !IsType(got, "notFound") {
+		t.Errorf("expected the copy to match the original")
+	}
+
+	original := sentinel.(*StructuredError)
+	if original.RequestID() != "" || original.tags.JsonValueString() != `{"route":"/admin"}` {
+		t.Errorf("expected the original not to be changed, got %s %s", original.RequestID(), original.tags.JsonValueString())
+	}
+
+	wrapped := CopyWithContext(newTestContext(), errors.New("boom")).(*StructuredError)
+	if wrapped.RequestID() != "req-ctx" {
+		t.Errorf("expected request id req-ctx, got %q", wrapped.RequestID())
+	}
+	if CopyWithContext(newTestContext(), nil) != nil {
+		t.Errorf("expected nil")
+	}
+}
+
+func TestNewCtx(t *testing.T) {
+	err := NewCtx(newTestContext(), "boom")
+	fe, ok := err.(*StructuredError)
+	if !ok {
+		t.Fatalf("expected *StructuredError, got %T", err)
+	}
+	if fe.Error() != "[Type: none] boom" {
+		t.Errorf("unexpected Error(): %s", fe.Error())
+	}
+	if fe.RequestID() != "req-ctx" {
+		t.Errorf("expected req-ctx, got %s", fe.RequestID())
+	}
+	st := fe.StackTrace()
+	if len(st) == 0 || st[0].Function != "github.com/hinoguma/go-structured-error.TestNewCtx" {
+		t.Errorf("expected stack trace to start at caller of NewCtx, got %v", st)
+	}
+}
+
+type testTraceKey struct{}
+
+func TestSetDefaultContextExtractor(t *testing.T) {
+	SetDefaultContextExtractor(ContextExtractors(
+		TraceContextExtractor(func(ctx context.Context) (string, string) {
+			ids, _ := ctx.Value(testTraceKey{}).([2]string)
+			return ids[0], ids[1]
+		}),
+		ContextExtractorFunc(func(ctx context.Context, err SError) {
+			_ = err.AddTagSafe("tenant", StringTagValue("acme"))
+		}),
+	))
+	defer SetDefaultContextExtractor(nil)
+
+	ctx := context.WithValue(ContextWithRequestID(context.Background(), "req-1"), testTraceKey{}, [2]string{"4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7"})
+	got := NewCtx(ctx, "boom").(*StructuredError)
+	expected := `{"trace_id":"4bf92f3577b34da6a3ce929d0e0e4736","span_id":"00f067aa0ba902b7","tenant":"acme"}`
+	if got.tags.JsonValueString() != expected {
+		t.Errorf("expected %s, got %s", expected, got.tags.JsonValueString())
+	}
+
+	// empty ids are not added
+	got = NewCtx(context.Background(), "boom").(*StructuredError)
+	if got.tags.JsonValueString() != `{"tenant":"acme"}` {
+		t.Errorf("expected only tenant tag, got %s", got.tags.JsonValueString())
+	}
+}
+
+func TestFromContext(t *testing.T) {
+	errTimeout := errors.New("upstream too slow")
+
+	canceled, cancel := context.WithCancelCause(context.Background())
+	cancel(errors.New("client closed connection"))
+
+	expired, cancelExpired := context.WithDeadlineCause(context.Background(), time.Now().Add(-time.Second), errTimeout)
+	defer cancelExpired()
+
+	alive, cancelAlive := context.WithTimeout(context.Background(), time.Hour)
+	defer cancelAlive()
+
+	testCases := []struct {
+		label        string
+		ctx          context.Context
+		err          error
+		expectedType ErrorType
+		cause        string
+		hasDeadline  bool
+		hasRemaining bool
+		hasOverrun   bool
+	}{
+		{
+			label:        "canceled with cause",
+			ctx:          canceled,
+			err:          canceled.Err(),
+			expectedType: TypeCanceled,
+			cause:        "client closed connection",
+		},
+		{
+			label:        "deadline exceeded with cause",
+			ctx:          expired,
+			err:          fmt.Errorf("call upstream: %w", expired.Err()),
+			expectedType: TypeDeadlineExceeded,
+			cause:        "upstream too slow",
+			hasDeadline:  true,
+			hasOverrun:   true,
+		},
+		{
+			label:        "type already set is kept",
+			ctx:          expired,
+			err:          With(expired.Err(), WithType("upstream")),
+			expectedType: "upstream",
+			cause:        "upstream too slow",
+			hasDeadline:  true,
+			hasOverrun:   true,
+		},
+		{
+			label:        "context alive",
+			ctx:          alive,
+			err:          errors.New("boom"),
+			expectedType: ErrorTypeNone,
+			hasDeadline:  true,
+			hasRemaining: true,
+		},
+		{
+			label:        "no deadline",
+			ctx:          context.Background(),
+			err:          context.Canceled,
+			expectedType: TypeCanceled,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.label, func(t *testing.T) {
+			got := FromContext(tc.ctx, tc.err).(*StructuredError)
+			if got.Type() != tc.expectedType {
+				t.Errorf("expected type %s, got %s", tc.expectedType, got.Type())
+			}
+			if !errors.Is(got, tc.err) {
+				t.Errorf("expected errors.Is(got, err) to be true")
+			}
+			cause, ok := got.tags.GetValue(ContextCauseTagKey)
+			if tc.cause == "" && ok {
+				t.Errorf("expected no cause, got %v", cause)
+			}
+			if tc.cause != "" && (!ok || cause.String() != tc.cause) {
+				t.Errorf("expected cause %s, got %v", tc.cause, cause)
+			}
+			if _, ok := got.tags.GetValue(ContextDeadlineTagKey); ok != tc.hasDeadline {
+				t.Errorf("expected deadline tag %v, got %v", tc.hasDeadline, ok)
+			}
+			if v, ok := got.tags.GetValue(ContextRemainingTagKey); ok != tc.hasRemaining || (ok && time.Duration(v.(DurationTagValue)) <= 0) {
+				t.Errorf("expected positive remaining tag %v, got %v", tc.hasRemaining, v)
+			}
+			if v, ok := got.tags.GetValue(ContextOverrunTagKey); ok != tc.hasOverrun || (ok && time.Duration(v.(DurationTagValue)) < time.Second) {
+				t.Errorf("expected overrun tag %v, got %v", tc.hasOverrun, v)
+			}
+			if len(got.StackTrace()) == 0 {
+				t.Errorf("expected stack trace to be set")
+			}
+		})
+	}
+
+	if FromContext(context.Background(), nil) != nil {
+		t.Errorf("expected nil for nil error")
+	}
+	if !IsTypeOrChild(FromContext(expired, expired.Err()), "context") {
+		t.Errorf("expected context types to be children of context")
+	}
+}
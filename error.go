@@ -42,12 +42,35 @@ func Wrap(err error, msg string) error {
 		return nil
 	}
 	fe := ToStructured(err)
-	if len(fe.StackTrace()) == 0 {
+	if !hasStackTrace(fe) {
 		_ = fe.WithStackTrace()
 	}
 	return fe.SetErr(fmt.Errorf("%s: %w", msg, fe.Unwrap()))
 }
 
+// compatibility functions for fmt.Errorf
+// Errorf() formats like fmt.Errorf() and sets stack trace starting from caller of Errorf.
+// multiple %w verbs are supported, and errors.Is(), errors.As() and IsType() look into all of them.
+func Errorf(format string, args ...any) error {
+	fe := NewRawStructuredError(fmt.Errorf(format, args...))
+	_ = fe.SetStackTraceWithSkipMaxDepth(2, MaxStackTraceDepth) // skip 2 to start at caller of Errorf
+	return fe
+}
+
+// Wrapf() is similar to Wrap() but the message is formatted like fmt.Errorf().
+// if format has %w verbs, the errors are wrapped together with err.
+// stack trace is set starting from caller of Wrapf only if err has no stack trace.
+func Wrapf(err error, format string, args ...any) error {
+	if err == nil {
+		return nil
+	}
+	fe := ToStructured(err)
+	if !hasStackTrace(fe) {
+		_ = fe.SetStackTraceWithSkipMaxDepth(2, MaxStackTraceDepth) // skip 2 to start at caller of Wrapf
+	}
+	return fe.SetErr(fmt.Errorf(format+": %w", append(args[:len(args):len(args)], fe.Unwrap())...))
+}
+
 // Lift() is similar to Wrap() but now wrapping with message
 // Lift() converts any error to SError
 // if the error is already SError, it just adds stack trace if missing
@@ -56,12 +79,20 @@ func Lift(err error) error {
 		return nil
 	}
 	fe := ToStructured(err)
-	if len(fe.StackTrace()) == 0 {
+	if !hasStackTrace(fe) {
 		_ = fe.WithStackTrace()
 	}
 	return fe
 }
 
+// hasStackTrace checks stack trace without resolving frames if possible
+func hasStackTrace(fe SError) bool {
+	if h, ok := fe.(interface{ HasStackTrace() bool }); ok {
+		return h.HasStackTrace()
+	}
+	return len(fe.StackTrace()) > 0
+}
+
 type causer interface {
 	Cause() error
 }
@@ -91,26 +122,96 @@ func Cause(err error) error {
 	}
 }
 
-// IsType() checks whether the given error or any of its wrapped errors is of the specified ErrorType.
+// IsType() checks whether the given error or any of its wrapped errors and sub errors is of the specified ErrorType.
 // errors.Is() checks for error equality, but this function checks for error type.
 func IsType(err error, t ErrorType) bool {
+	return matchType(err, func(et ErrorType) bool { return et == t })
+}
+
+// IsTypeOrChild() is similar to IsType() but also matches child types of t.
+// e.g. "db.timeout" and "db.conflict" match "db". See ErrorType.Parent() for hierarchy of types.
+func IsTypeOrChild(err error, t ErrorType) bool {
+	return matchType(err, func(et ErrorType) bool { return et.IsChildOf(t) })
+}
+
+// matchType reports whether the type of err, its wrapped errors or sub errors satisfies match
+func matchType(err error, match func(ErrorType) bool) bool {
 	if err == nil {
 		return false
 	}
 	fe, ok := err.(HasType)
-	if ok && fe.Type() == t {
+	if ok && match(fe.Type()) {
 		return true
 	}
 
 	switch x := err.(type) {
 	case interface{ Unwrap() error }:
-		return IsType(x.Unwrap(), t)
+		if matchType(x.Unwrap(), match) {
+			return true
+		}
 	case interface{ Unwrap() []error }:
 		for _, subErr := range x.Unwrap() {
-			if IsType(subErr, t) {
+			if matchType(subErr, match) {
+				return true
+			}
+		}
+	}
+
+	if x, ok := err.(HasSubErrors); ok {
+		for _, subErr := range x.SubErrors() {
+			if matchType(subErr, match) {
 				return true
 			}
 		}
 	}
 	return false
 }
+
+// WalkChain calls fn with err and its wrapped errors in depth-first order until fn returns true
+// errors joined by Unwrap() []error are walked in order. sub errors are not looked into.
+// it reports whether fn returned true.
+func WalkChain(err error, fn func(error) bool) bool {
+	for err != nil {
+		if fn(err) {
+			return true
+		}
+		if x, ok := err.(interface{ Unwrap() []error }); ok {
+			for _, wrapped := range x.Unwrap() {
+				if WalkChain(wrapped, fn) {
+					return true
+				}
+			}
+			return false
+		}
+		err = errors.Unwrap(err)
+	}
+	return false
+}
+
+// OutermostType returns the type of the outermost error in the chain of err whose type is not ErrorTypeNone
+// sub errors are not looked into. if no error has a type, it returns ErrorTypeNone.
+func OutermostType(err error) ErrorType {
+	t := ErrorTypeNone
+	WalkChain(err, func(e error) bool {
+		if ht, ok := e.(HasType); ok && ht.Type() != ErrorTypeNone {
+			t = ht.Type()
+			return true
+		}
+		return false
+	})
+	return t
+}
+
+// OutermostRequestID returns the request id of the outermost error in the chain of err having it
+// sub errors are not looked into. if no error has a request id, it returns empty string.
+func OutermostRequestID(err error) string {
+	id := ""
+	WalkChain(err, func(e error) bool {
+		if fe, ok := e.(interface{ RequestID() string }); ok && fe.RequestID() != "" {
+			id = fe.RequestID()
+			return true
+		}
+		return false
+	})
+	return id
+}
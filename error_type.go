@@ -0,0 +1,55 @@
+package serrors
+
+import "strings"
+
+// ErrorTypeSeparator separates levels of hierarchical error types like "db.timeout"
+const ErrorTypeSeparator string = "."
+
+// Parent returns the parent type of value
+// the parent registered by RegisterType() takes precedence over the dot-separated prefix.
+// e.g. "db.timeout" -> "db", "db" -> ErrorTypeNone
+func (value ErrorType) Parent() ErrorType {
+	if info, ok := LookupType(value); ok && info.Parent != ErrorTypeNone {
+		return info.Parent
+	}
+	i := strings.LastIndex(string(value), ErrorTypeSeparator)
+	if i < 0 {
+		return ErrorTypeNone
+	}
+	return value[:i]
+}
+
+// Ancestors returns the parent, the grandparent and so on up to the root type
+// e.g. "db.sql.timeout" -> ["db.sql", "db"]
+// if registered parents make a cycle, it stops before the type appears again.
+func (value ErrorType) Ancestors() []ErrorType {
+	ancestors := make([]ErrorType, 0)
+	for t := value.Parent(); t != ErrorTypeNone; t = t.Parent() {
+		if t == value || containsErrorType(ancestors, t) {
+			break
+		}
+		ancestors = append(ancestors, t)
+	}
+	return ancestors
+}
+
+// IsChildOf reports whether value is t or a descendant of t
+// ErrorTypeNone is not a parent of any type.
+func (value ErrorType) IsChildOf(t ErrorType) bool {
+	if value == t {
+		return true
+	}
+	if t == ErrorTypeNone {
+		return false
+	}
+	return containsErrorType(value.Ancestors(), t)
+}
+
+func containsErrorType(types []ErrorType, t ErrorType) bool {
+	for _, v := range types {
+		if v == t {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,143 @@
+package serrors
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestErrorType_Parent(t *testing.T) {
+	RegisterType("checkout", TypeInfo{Parent: "payment"})
+	defer UnregisterType("checkout")
+
+	testCases := []struct {
+		label    string
+		t        ErrorType
+		expected ErrorType
+	}{
+		{label: "none", t: ErrorTypeNone, expected: ErrorTypeNone},
+		{label: "root", t: "db", expected: ErrorTypeNone},
+		{label: "child", t: "db.timeout", expected: "db"},
+		{label: "grandchild", t: "db.sql.timeout", expected: "db.sql"},
+		{label: "registered parent", t: "checkout", expected: "payment"},
+		{label: "trailing separator", t: "db.", expected: "db"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.label, func(t *testing.T) {
+			if got := tc.t.Parent(); got != tc.expected {
+				t.Errorf("expected %q, got %q", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestErrorType_Ancestors(t *testing.T) {
+	RegisterType("checkout.card", TypeInfo{Parent: "payment.card"})
+	RegisterType("loopA", TypeInfo{Parent: "loopB"})
+	RegisterType("loopB", TypeInfo{Parent: "loopA"})
+	defer UnregisterType("checkout.card")
+	defer UnregisterType("loopA")
+	defer UnregisterType("loopB")
+
+	testCases := []struct {
+		label    string
+		t        ErrorType
+		expected []ErrorType
+	}{
+		{label: "root", t: "db", expected: []ErrorType{}},
+		{label: "grandchild", t: "db.sql.timeout", expected: []ErrorType{"db.sql", "db"}},
+		{label: "registered parent", t: "checkout.card", expected: []ErrorType{"payment.card", "payment"}},
+		{label: "cycle", t: "loopA", expected: []ErrorType{"loopB"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.label, func(t *testing.T) {
+			if got := tc.t.Ancestors(); !reflect.DeepEqual(got, tc.expected) {
+				t.Errorf("expected %v, got %v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestIsTypeOrChild(t *testing.T) {
+	RegisterType("checkout", TypeInfo{Parent: "payment"})
+	defer UnregisterType("checkout")
+
+	testCases := []struct {
+		label       string
+		err         error
+		t           ErrorType
+		expected    bool
+		expectExact bool
+	}{
+		{
+			label:    "nil error",
+			err:      nil,
+			t:        "db",
+			expected: false,
+		},
+		{
+			label:       "same type",
+			err:         newTypedError("db", "boom"),
+			t:           "db",
+			expected:    true,
+			expectExact: true,
+		},
+		{
+			label:    "child type",
+			err:      newTypedError("db.timeout", "boom"),
+			t:        "db",
+			expected: true,
+		},
+		{
+			label:    "grandchild type wrapped by fmt.Errorf",
+			err:      fmt.Errorf("query: %w", newTypedError("db.sql.conflict", "boom")),
+			t:        "db",
+			expected: true,
+		},
+		{
+			label:    "parent does not match child",
+			err:      newTypedError("db", "boom"),
+			t:        "db.timeout",
+			expected: false,
+		},
+		{
+			label:    "prefix is not a parent",
+			err:      newTypedError("dbx.timeout", "boom"),
+			t:        "db",
+			expected: false,
+		},
+		{
+			label:    "registered parent",
+			err:      newTypedError("checkout", "boom"),
+			t:        "payment",
+			expected: true,
+		},
+		{
+			label: "child type in sub errors",
+			err: NewRawStructuredError(errors.New("main")).
+				AddSubError(newTypedError("db.timeout", "sub")),
+			t:        "db",
+			expected: true,
+		},
+		{
+			label:    "none type matches nothing",
+			err:      newTypedError("db.timeout", "boom"),
+			t:        ErrorTypeNone,
+			expected: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.label, func(t *testing.T) {
+			if got := IsTypeOrChild(tc.err, tc.t); got != tc.expected {
+				t.Errorf("IsTypeOrChild() expected %v, got %v", tc.expected, got)
+			}
+			if got := IsType(tc.err, tc.t); got != tc.expectExact {
+				t.Errorf("IsType() expected %v, got %v", tc.expectExact, got)
+			}
+		})
+	}
+}
@@ -324,6 +324,120 @@ func TestWrap(t *testing.T) {
 	}
 }
 
+func TestErrorf(t *testing.T) {
+	errA := errors.New("error a")
+	errB := With(errors.New("error b"), WithType(testErrorType1))
+
+	testCases := []struct {
+		label       string
+		format      string
+		args        []any
+		message     string
+		isTargets   []error
+		isType      ErrorType
+		expectedLen int
+	}{
+		{
+			label:   "no verbs",
+			format:  "plain message",
+			message: "[Type: none] plain message",
+		},
+		{
+			label:   "formatted",
+			format:  "user %d not found",
+			args:    []any{42},
+			message: "[Type: none] user 42 not found",
+		},
+		{
+			label:     "single %w",
+			format:    "query: %w",
+			args:      []any{errA},
+			message:   "[Type: none] query: error a",
+			isTargets: []error{errA},
+		},
+		{
+			label:     "multiple %w",
+			format:    "%w and %w",
+			args:      []any{errA, errB},
+			message:   "[Type: none] error a and [Type: testCustom1] error b",
+			isTargets: []error{errA, errB},
+			isType:    testErrorType1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.label, func(t *testing.T) {
+			got := Errorf(tc.format, tc.args...)
+			fe, ok := got.(*StructuredError)
+			if !ok {
+				t.Fatalf("expected *StructuredError, got %T", got)
+			}
+			if fe.Error() != tc.message {
+				t.Errorf("expected %s, got %s", tc.message, fe.Error())
+			}
+			for _, target := range tc.isTargets {
+				if !errors.Is(got, target) {
+					t.Errorf("expected errors.Is(got, %v) to be true", target)
+				}
+			}
+			if tc.isType != ErrorTypeNone && !IsType(got, tc.isType) {
+				t.Errorf("expected IsType(got, %s) to be true", tc.isType)
+			}
+			st := fe.StackTrace()
+			if len(st) == 0 || st[0].Function != "github.com/hinoguma/go-structured-error.TestErrorf.func1" {
+				t.Errorf("expected stack trace to start at caller of Errorf, got %v", st)
+			}
+		})
+	}
+}
+
+func TestWrapf(t *testing.T) {
+	errA := errors.New("error a")
+
+	t.Run("nil error", func(t *testing.T) {
+		if got := Wrapf(nil, "should be %s", "nil"); got != nil {
+			t.Errorf("expected nil, got %v", got)
+		}
+	})
+
+	t.Run("wrap standard error", func(t *testing.T) {
+		got := Wrapf(errStd, "user %d", 42)
+		expected := NewRawStructuredError(fmt.Errorf("user 42: %w", errStd))
+		assertEqualsStructuredWithoutStackTrace(t, got.(SError), expected)
+		st := got.(SError).StackTrace()
+		if len(st) == 0 || st[0].Function != "github.com/hinoguma/go-structured-error.TestWrapf.func2" {
+			t.Errorf("expected stack trace to start at caller of Wrapf, got %v", st)
+		}
+	})
+
+	t.Run("wrap fault error keeps type and stack trace", func(t *testing.T) {
+		original := With(New("original"), WithType(testErrorType1))
+		originalStack := original.(SError).StackTrace()
+		got := Wrapf(original, "user %d", 42)
+		if got.Error() != "[Type: testCustom1] user 42: original" {
+			t.Errorf("unexpected Error(): %s", got.Error())
+		}
+		if !reflect.DeepEqual(got.(SError).StackTrace(), originalStack) {
+			t.Errorf("expected stack trace not to be re-captured")
+		}
+	})
+
+	t.Run("%w in format", func(t *testing.T) {
+		args := make([]any, 1, 2)
+		args[0] = errA
+		got := Wrapf(errStd, "with %w", args...)
+		if got.Error() != "[Type: none] with error a: "+errStd.Error() {
+			t.Errorf("unexpected Error(): %s", got.Error())
+		}
+		if !errors.Is(got, errA) || !errors.Is(got, errStd) {
+			t.Errorf("expected both errors to be matched by errors.Is()")
+		}
+		if args[:2][1] != nil {
+			t.Errorf("args of caller must not be modified")
+		}
+	})
+}
+
 func TestLift(t *testing.T) {
 	testCases := []struct {
 		label    string
@@ -568,3 +682,40 @@ func TestCause(t *testing.T) {
 		})
 	}
 }
+
+func TestOutermostTypeAndRequestID(t *testing.T) {
+	typed := With(errors.New("inner"), WithType("inner"), WithRequestID("req-inner"))
+	testCases := []struct {
+		label             string
+		err               error
+		expectedType      ErrorType
+		expectedRequestID string
+	}{
+		{label: "nil", err: nil, expectedType: ErrorTypeNone},
+		{label: "standard error", err: errors.New("boom"), expectedType: ErrorTypeNone},
+		{label: "wrapped", err: fmt.Errorf("wrap: %w", typed), expectedType: "inner", expectedRequestID: "req-inner"},
+		{
+			label:             "outer without type and request id",
+			err:               Wrap(fmt.Errorf("wrap: %w", typed), "outer"),
+			expectedType:      "inner",
+			expectedRequestID: "req-inner",
+		},
+		{
+			label:             "outer with type and request id",
+			err:               With(fmt.Errorf("wrap: %w", typed), WithType("outer"), WithRequestID("req-outer")),
+			expectedType:      "outer",
+			expectedRequestID: "req-outer",
+		},
+		{label: "joined", err: errors.Join(errors.New("a"), typed), expectedType: "inner", expectedRequestID: "req-inner"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.label, func(t *testing.T) {
+			if got := OutermostType(tc.err); got != tc.expectedType {
+				t.Errorf("expected type %q, got %q", tc.expectedType, got)
+			}
+			if got := OutermostRequestID(tc.err); got != tc.expectedRequestID {
+				t.Errorf("expected request id %q, got %q", tc.expectedRequestID, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,145 @@
+package serrors
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// tag keys of sub errors of the error returned by Group.Wait()
+const (
+	GoroutineIndexTagKey string = "goroutine_index"
+	GoroutineNameTagKey  string = "goroutine_name"
+)
+
+// Go runs fn in a new goroutine and calls onErr with its error
+// panics are recovered by RecoverValue(), and returned errors are wrapped by a new StructuredError
+// with the stack trace of the caller of Go, so errors shared by goroutines are not changed.
+// onErr is called in the goroutine. if onErr is nil, errors are ignored.
+func Go(fn func() error, onErr func(SError)) {
+	launched := newCallers(1, MaxStackTraceDepth) // skip 1 to start at caller of Go
+	go func() {
+		fe := runGoroutine(fn, launched)
+		if fe != nil && onErr != nil {
+			onErr(fe)
+		}
+	}()
+}
+
+// runGoroutine runs fn and converts its error or panic into a new SError
+// the returned error is the cause of the new SError and is not changed.
+func runGoroutine(fn func() error, launched *callers) (fe SError) {
+	defer func() {
+		if r := recover(); r != nil {
+			fe = RecoverValue(r)
+		}
+	}()
+	err := fn()
+	if err == nil {
+		return nil
+	}
+	wrapped := NewRawStructuredError(err)
+	wrapped.callers = launched
+	return wrapped
+}
+
+// Group is a collection of goroutines like errgroup.Group of golang.org/x/sync
+// Unlike errgroup, Wait() returns all failures, and panics are recovered into errors of TypePanic.
+// The zero Group is valid, has no limit and does not cancel on errors.
+type Group struct {
+	cancel context.CancelCauseFunc
+	wg     sync.WaitGroup
+	sem    chan struct{}
+
+	mu   sync.Mutex
+	n    int
+	errs []error
+}
+
+// GroupWithContext returns a new Group and a context derived from ctx
+// The context is canceled with the first failure as the cause, or when Wait() returns.
+func GroupWithContext(ctx context.Context) (*Group, context.Context) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	return &Group{cancel: cancel}, ctx
+}
+
+// SetLimit limits the number of active goroutines to n. n <= 0 means no limit.
+// It must not be called while goroutines are active.
+func (g *Group) SetLimit(n int) {
+	if n <= 0 {
+		g.sem = nil
+		return
+	}
+	g.sem = make(chan struct{}, n)
+}
+
+// Go runs fn in a new goroutine, blocking until the limit set by SetLimit() allows it
+// errors are wrapped by a new StructuredError with the stack trace of the caller of Go like the package level Go().
+func (g *Group) Go(fn func() error) {
+	g.goNamed("", fn, newCallers(1, MaxStackTraceDepth)) // skip 1 to start at caller of Go
+}
+
+// GoNamed is similar to Go() but the failure is tagged with name too
+func (g *Group) GoNamed(name string, fn func() error) {
+	g.goNamed(name, fn, newCallers(1, MaxStackTraceDepth)) // skip 1 to start at caller of GoNamed
+}
+
+func (g *Group) goNamed(name string, fn func() error, launched *callers) {
+	if g.sem != nil {
+		g.sem <- struct{}{}
+	}
+	g.mu.Lock()
+	index := g.n
+	g.n++
+	g.errs = append(g.errs, nil)
+	g.mu.Unlock()
+
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		defer func() {
+			if g.sem != nil {
+				<-g.sem
+			}
+		}()
+		fe := runGoroutine(fn, launched)
+		if fe == nil {
+			return
+		}
+		_ = fe.AddTagSafe(GoroutineIndexTagKey, IntTagValue(index))
+		if name != "" {
+			_ = fe.AddTagSafe(GoroutineNameTagKey, StringTagValue(name))
+		}
+		g.mu.Lock()
+		g.errs[index] = fe
+		g.mu.Unlock()
+		if g.cancel != nil {
+			g.cancel(fe)
+		}
+	}()
+}
+
+// Wait waits for all goroutines and returns StructuredError having all failures as sub errors in the order of Go() calls
+// errors.Is(), errors.As() and IsType() look into the failures. if no goroutine failed, it returns nil.
+func (g *Group) Wait() error {
+	g.wg.Wait()
+	if g.cancel != nil {
+		g.cancel(nil)
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	failures := make([]error, 0, len(g.errs))
+	for _, err := range g.errs {
+		if err != nil {
+			failures = append(failures, err)
+		}
+	}
+	if len(failures) == 0 {
+		return nil
+	}
+	fe := NewRawStructuredError(fmt.Errorf("%d of %d goroutines failed", len(failures), g.n))
+	_ = fe.SetStackTraceWithSkipMaxDepth(2, MaxStackTraceDepth) // skip 2 to start at caller of Wait
+	_ = fe.AddSubError(failures...)
+	return fe
+}
@@ -0,0 +1,179 @@
+package serrors
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestGo(t *testing.T) {
+	sentinel := errors.New("sentinel")
+	testCases := []struct {
+		label         string
+		fn            func() error
+		expectedType  ErrorType
+		expectedStack string
+	}{
+		{
+			label:         "returned error",
+			fn:            func() error { return sentinel },
+			expectedType:  ErrorTypeNone,
+			expectedStack: "github.com/hinoguma/go-structured-error.TestGo",
+		},
+		{
+			label:         "panic",
+			fn:            func() error { panicking("boom"); return nil },
+			expectedType:  TypePanic,
+			expectedStack: "github.com/hinoguma/go-structured-error.panicking",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.label, func(t *testing.T) {
+			errCh := make(chan SError, 1)
+			Go(tc.fn, func(fe SError) { errCh <- fe })
+			fe := <-errCh
+			if fe.Type() != tc.expectedType {
+				t.Errorf("expected type %s, got %s", tc.expectedType, fe.Type())
+			}
+			st := fe.StackTrace()
+			if len(st) == 0 || !strings.HasPrefix(st[0].Function, tc.expectedStack) {
+				t.Errorf("expected stack trace to start at %s, got %v", tc.expectedStack, st)
+			}
+		})
+	}
+}
+
+func TestGo_NilOnErr(t *testing.T) {
+	done := make(chan struct{})
+	Go(func() error {
+		defer close(done)
+		panicking("boom")
+		return nil
+	}, nil)
+	<-done
+}
+
+func TestGroup(t *testing.T) {
+	sentinel := errors.New("sentinel")
+	g := &Group{}
+	g.Go(func() error { return nil })
+	g.GoNamed("fetch", func() error { return sentinel })
+	g.Go(func() error { panicking("boom"); return nil })
+
+	err := g.Wait()
+	if err == nil {
+		t.Fatalf("expected error")
+	}
+	if err.Error() != "[Type: none] 2 of 3 goroutines failed" {
+		t.Errorf("unexpected message %s", err.Error())
+	}
+	if !errors.Is(err, sentinel) || !IsType(err, TypePanic) {
+		t.Errorf("expected failures to be sub errors, got %v", err)
+	}
+	subErrors := err.(*StructuredError).SubErrors()
+	if len(subErrors) != 2 {
+		t.Fatalf("expected 2 sub errors, got %d", len(subErrors))
+	}
+	expectedTags := []string{
+		`{"goroutine_index":1,"goroutine_name":"fetch"}`,
+		`{"panic_value":"boom","goroutine_index":2}`,
+	}
+	for i, subErr := range subErrors {
+		tags := subErr.(*StructuredError).Tags().JsonValueString()
+		if tags != expectedTags[i] {
+			t.Errorf("expected tags %s, got %s", expectedTags[i], tags)
+		}
+	}
+	st := err.(*StructuredError).StackTrace()
+	if len(st) == 0 || st[0].Function != "github.com/hinoguma/go-structured-error.TestGroup" {
+		t.Errorf("expected stack trace to start at caller of Wait, got %v", st)
+	}
+}
+
+func TestGroup_NoError(t *testing.T) {
+	g := &Group{}
+	g.Go(func() error { return nil })
+	if err := g.Wait(); err != nil {
+		t.Errorf("expected nil, got %v", err)
+	}
+}
+
+func TestGroupWithContext(t *testing.T) {
+	sentinel := errors.New("sentinel")
+	g, ctx := GroupWithContext(context.Background())
+	g.Go(func() error {
+		<-ctx.Done()
+		return nil
+	})
+	g.Go(func() error { return sentinel })
+
+	if err := g.Wait(); !errors.Is(err, sentinel) {
+		t.Errorf("expected sentinel, got %v", err)
+	}
+	if !errors.Is(context.Cause(ctx), sentinel) {
+		t.Errorf("expected the failure to be the cause, got %v", context.Cause(ctx))
+	}
+}
+
+func TestGroup_SetLimit(t *testing.T) {
+	g := &Group{}
+	g.SetLimit(2)
+	var active, maxActive int32
+	for i := 0; i < 10; i++ {
+		g.Go(func() error {
+			n := atomic.AddInt32(&active, 1)
+			for {
+				m := atomic.LoadInt32(&maxActive)
+				if n <= m || atomic.CompareAndSwapInt32(&maxActive, m, n) {
+					break
+				}
+			}
+			time.Sleep(time.Millisecond)
+			atomic.AddInt32(&active, -1)
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if maxActive > 2 {
+		t.Errorf("expected at most 2 active goroutines, got %d", maxActive)
+	}
+}
+
+func TestGroup_SharedError(t *testing.T) {
+	sentinel := With(errors.New("not found"), WithType("notFound"))
+	g := &Group{}
+	for i := 0; i < 20; i++ {
+		g.GoNamed("fetch", func() error { return sentinel })
+	}
+	errCh := make(chan SError, 20)
+	for i := 0; i < 20; i++ {
+		Go(func() error { return sentinel }, func(fe SError) { errCh <- fe })
+	}
+
+	err := g.Wait()
+	if !errors.Is(err, sentinel) || !IsType(err, "notFound") {
+		t.Errorf("expected failures to wrap the shared error, got %v", err)
+	}
+	for i, subErr := range err.(*StructuredError).SubErrors() {
+		v, ok := subErr.(*StructuredError).Tags().GetValue(GoroutineIndexTagKey)
+		if !ok || v.String() != strconv.Itoa(i) {
+			t.Errorf("expected goroutine index %d, got %v", i, v)
+		}
+	}
+	for i := 0; i < 20; i++ {
+		if fe := <-errCh; !errors.Is(fe, sentinel) || !hasStackTrace(fe) {
+			t.Errorf("expected the shared error with stack trace, got %v", fe)
+		}
+	}
+
+	original := sentinel.(*StructuredError)
+	if len(original.Tags().List()) != 0 || original.HasStackTrace() {
+		t.Errorf("expected the shared error not to be changed, got %s", original.JsonString())
+	}
+}
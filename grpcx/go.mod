@@ -0,0 +1,20 @@
+module github.com/hinoguma/go-structured-error/grpcx
+
+go 1.22
+
+require (
+	github.com/hinoguma/go-structured-error v0.0.0-20261016205649-aeb3ba5f5635
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20240528184218-531527333157
+	google.golang.org/grpc v1.65.0
+	google.golang.org/protobuf v1.34.1
+)
+
+require (
+	golang.org/x/net v0.25.0 // indirect
+	golang.org/x/sys v0.20.0 // indirect
+	golang.org/x/text v0.15.0 // indirect
+)
+
+// grpcx uses APIs of the root module which are not tagged yet.
+// drop this replace once the root module is tagged and required above.
+replace github.com/hinoguma/go-structured-error => ../
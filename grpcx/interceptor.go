@@ -0,0 +1,50 @@
+package grpcx
+
+import (
+	"context"
+
+	serrors "github.com/hinoguma/go-structured-error"
+	"google.golang.org/grpc"
+)
+
+// TypePanic is serrors.TypePanic, the type of errors recovered from panics by interceptors
+const TypePanic = serrors.TypePanic
+
+// UnaryServerInterceptor recovers panics into StructuredError of TypePanic with stack trace,
+// and converts returned errors into status errors by ToStatus
+// if opts is nil, default options are used.
+func UnaryServerInterceptor(opts *Options) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+		defer func() {
+			if v := recover(); v != nil {
+				resp = nil
+				err = serrors.RecoverValueForClients(v)
+			}
+			err = convertError(ctx, err, opts)
+		}()
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor is the stream version of UnaryServerInterceptor
+func StreamServerInterceptor(opts *Options) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		defer func() {
+			if v := recover(); v != nil {
+				err = serrors.RecoverValueForClients(v)
+			}
+			err = convertError(ss.Context(), err, opts)
+		}()
+		return handler(srv, ss)
+	}
+}
+
+func convertError(ctx context.Context, err error, opts *Options) error {
+	if err == nil {
+		return nil
+	}
+	if opts != nil && opts.OnError != nil {
+		opts.OnError(ctx, err)
+	}
+	return ToStatus(err, opts).Err()
+}
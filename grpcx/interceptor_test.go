@@ -0,0 +1,131 @@
+package grpcx
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+
+	serrors "github.com/hinoguma/go-structured-error"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// testHealthServer returns errors or panics by the service name of requests
+type testHealthServer struct {
+	grpc_health_v1.UnimplementedHealthServer
+}
+
+func (s *testHealthServer) Check(ctx context.Context, req *grpc_health_v1.HealthCheckRequest) (*grpc_health_v1.HealthCheckResponse, error) {
+	return s.respond(req.GetService())
+}
+
+func (s *testHealthServer) Watch(req *grpc_health_v1.HealthCheckRequest, stream grpc_health_v1.Health_WatchServer) error {
+	resp, err := s.respond(req.GetService())
+	if err != nil {
+		return err
+	}
+	return stream.Send(resp)
+}
+
+func (s *testHealthServer) respond(service string) (*grpc_health_v1.HealthCheckResponse, error) {
+	switch service {
+	case "panic":
+		panic("something broke")
+	case "error":
+		return nil, serrors.Builder(errors.New("db is down")).
+			Type("grpcxUnavailable").
+			PublicMessage("try again later").
+			Build()
+	}
+	return &grpc_health_v1.HealthCheckResponse{Status: grpc_health_v1.HealthCheckResponse_SERVING}, nil
+}
+
+func newTestClient(t *testing.T, opts *Options) grpc_health_v1.HealthClient {
+	t.Helper()
+	lis := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer(
+		grpc.UnaryInterceptor(UnaryServerInterceptor(opts)),
+		grpc.StreamInterceptor(StreamServerInterceptor(opts)),
+	)
+	grpc_health_v1.RegisterHealthServer(server, &testHealthServer{})
+	go func() { _ = server.Serve(lis) }()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return grpc_health_v1.NewHealthClient(conn)
+}
+
+func TestServerInterceptors(t *testing.T) {
+	serrors.RegisterType("grpcxUnavailable", serrors.TypeInfo{GRPCCode: int(codes.Unavailable)})
+	defer serrors.UnregisterType("grpcxUnavailable")
+
+	var logged []error
+	client := newTestClient(t, &Options{OnError: func(ctx context.Context, err error) { logged = append(logged, err) }})
+
+	testCases := []struct {
+		label   string
+		service string
+		code    codes.Code
+		message string
+		errType serrors.ErrorType
+	}{
+		{label: "ok", service: "", code: codes.OK, message: ""},
+		{label: "error", service: "error", code: codes.Unavailable, message: "try again later", errType: "grpcxUnavailable"},
+		{label: "panic", service: "panic", code: codes.Unknown, message: "internal error", errType: TypePanic},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.label, func(t *testing.T) {
+			calls := map[string]func() error{
+				"unary": func() error {
+					_, err := client.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{Service: tc.service})
+					return err
+				},
+				"stream": func() error {
+					stream, err := client.Watch(context.Background(), &grpc_health_v1.HealthCheckRequest{Service: tc.service})
+					if err != nil {
+						return err
+					}
+					_, err = stream.Recv()
+					return err
+				},
+			}
+			for name, call := range calls {
+				logged = nil
+				err := call()
+				st := status.Convert(err)
+				if st.Code() != tc.code || st.Message() != tc.message {
+					t.Errorf("%s: expected %v %q, got %v %q", name, tc.code, tc.message, st.Code(), st.Message())
+				}
+				if tc.code == codes.OK {
+					continue
+				}
+				if got := FromStatus(st); got.Type() != tc.errType {
+					t.Errorf("%s: expected type %s, got %s", name, tc.errType, got.Type())
+				}
+				if len(logged) != 1 || !serrors.IsType(logged[0], tc.errType) {
+					t.Fatalf("%s: expected the error to be passed to OnError, got %v", name, logged)
+				}
+				if tc.errType == TypePanic {
+					st := logged[0].(serrors.SError).StackTrace()
+					if len(st) == 0 || !strings.HasSuffix(st[0].Function, "(*testHealthServer).respond") {
+						t.Errorf("%s: expected stack trace to start at the panicking function, got %v", name, st)
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,166 @@
+// Package grpcx converts errors into gRPC status and back, and provides server interceptors
+// It is a separate module so that the core package does not depend on gRPC.
+package grpcx
+
+import (
+	"context"
+	"errors"
+
+	serrors "github.com/hinoguma/go-structured-error"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+)
+
+// DefaultDomain is the domain of ErrorInfo written by ToStatus
+const DefaultDomain string = "github.com/hinoguma/go-structured-error"
+
+// RequestIDMetadataKey is the key of the request id in metadata of ErrorInfo
+const RequestIDMetadataKey string = "request_id"
+
+// Options configures ToStatus and interceptors
+type Options struct {
+	// Domain is the domain of ErrorInfo. if it is empty, DefaultDomain is used.
+	Domain string
+	// CodeMapper chooses the code of the status.
+	// if it is nil, the code of the type registry, of a wrapped status error, or of context errors is used.
+	CodeMapper func(err error) codes.Code
+	// Debug adds DebugInfo with the internal message and the stack trace.
+	// never enable it for untrusted clients.
+	Debug bool
+	// OnError is called with every error converted by interceptors, e.g. for logging.
+	OnError func(ctx context.Context, err error)
+}
+
+// ToStatus converts err into status.Status
+//   - message is serrors.PublicMessage(err), so internal messages are not exposed unless Options.Debug is true
+//   - ErrorInfo has the error type as reason, and the request id and tags as metadata.
+//     tag values are written by String(), and SecretTagValue is written as serrors.RedactedStr
+//   - tags and the stack trace of DebugInfo are taken from the outermost SError in the chain, e.g. wrapped by fmt.Errorf()
+//
+// errors which are status errors themselves are returned as they are.
+// if err is nil, it returns the OK status. if opts is nil, default options are used.
+func ToStatus(err error, opts *Options) *status.Status {
+	if err == nil {
+		return status.New(codes.OK, "")
+	}
+	if _, isStructured := err.(serrors.SError); !isStructured {
+		if se, ok := err.(interface{ GRPCStatus() *status.Status }); ok {
+			return se.GRPCStatus()
+		}
+	}
+	o := Options{}
+	if opts != nil {
+		o = *opts
+	}
+	if o.Domain == "" {
+		o.Domain = DefaultDomain
+	}
+	code := defaultCode(err)
+	if o.CodeMapper != nil {
+		code = o.CodeMapper(err)
+	}
+
+	st := status.New(code, serrors.PublicMessage(err))
+	fe := outermostStructured(err)
+	info := &errdetails.ErrorInfo{
+		Reason:   serrors.OutermostType(err).String(),
+		Domain:   o.Domain,
+		Metadata: make(map[string]string),
+	}
+	if requestID := serrors.OutermostRequestID(err); requestID != "" {
+		info.Metadata[RequestIDMetadataKey] = requestID
+	}
+	if te, ok := fe.(interface{ Tags() serrors.Tags }); ok {
+		redactor := serrors.DefaultRedactor()
+		for _, tag := range te.Tags().List() {
+			if _, exists := info.Metadata[tag.Key]; exists {
+				continue
+			}
+			value := tag.Value
+			if redactor != nil {
+				value = redactor.Redact(tag.Key, value)
+			}
+			if value == nil {
+				info.Metadata[tag.Key] = "null"
+				continue
+			}
+			info.Metadata[tag.Key] = value.String()
+		}
+	}
+	details := []protoadapt.MessageV1{info}
+	if o.Debug {
+		debug := &errdetails.DebugInfo{Detail: err.Error()}
+		if fe != nil {
+			for _, frame := range fe.StackTrace() {
+				debug.StackEntries = append(debug.StackEntries, frame.String())
+			}
+		}
+		details = append(details, debug)
+	}
+	withDetails, detailsErr := st.WithDetails(details...)
+	if detailsErr != nil {
+		return st
+	}
+	return withDetails
+}
+
+// FromStatus converts st into SError wrapping st.Err()
+//   - type is the reason of ErrorInfo
+//   - public message is the message of st
+//   - request id and the other metadata of ErrorInfo are restored as StringTagValue tags
+//
+// errors.As() finds the status error, so ToStatus() returns the same code for it.
+// if st is nil or OK, it returns nil.
+func FromStatus(st *status.Status) serrors.SError {
+	if st == nil || st.Code() == codes.OK {
+		return nil
+	}
+	fe := serrors.NewRawStructuredError(st.Err())
+	_ = fe.SetPublicMessage(st.Message())
+	for _, detail := range st.Details() {
+		info, ok := detail.(*errdetails.ErrorInfo)
+		if !ok {
+			continue
+		}
+		_ = fe.SetType(serrors.ErrorType(info.Reason))
+		for key, value := range info.Metadata {
+			if key == RequestIDMetadataKey {
+				_ = fe.SetRequestID(value)
+				continue
+			}
+			_ = fe.AddTagSafe(key, serrors.StringTagValue(value))
+		}
+		break
+	}
+	return fe
+}
+
+// outermostStructured returns the outermost SError in the chain of err, or nil if there is none
+func outermostStructured(err error) serrors.SError {
+	var fe serrors.SError
+	serrors.WalkChain(err, func(e error) bool {
+		fe, _ = e.(serrors.SError)
+		return fe != nil
+	})
+	return fe
+}
+
+// defaultCode chooses the code by the type registry, a wrapped status error or context errors
+func defaultCode(err error) codes.Code {
+	if code := serrors.GRPCCode(err); code != serrors.DefaultGRPCCode {
+		return codes.Code(code)
+	}
+	var se interface{ GRPCStatus() *status.Status }
+	if errors.As(err, &se) {
+		return se.GRPCStatus().Code()
+	}
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return codes.DeadlineExceeded
+	case errors.Is(err, context.Canceled):
+		return codes.Canceled
+	}
+	return codes.Unknown
+}
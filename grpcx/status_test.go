@@ -0,0 +1,199 @@
+package grpcx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	serrors "github.com/hinoguma/go-structured-error"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestToStatus(t *testing.T) {
+	serrors.RegisterType("grpcxNotFound", serrors.TypeInfo{GRPCCode: int(codes.NotFound)})
+	defer serrors.UnregisterType("grpcxNotFound")
+
+	testCases := []struct {
+		label        string
+		err          error
+		opts         *Options
+		code         codes.Code
+		message      string
+		reason       string
+		metadata     map[string]string
+		hasDebugInfo bool
+	}{
+		{
+			label:   "nil error",
+			err:     nil,
+			code:    codes.OK,
+			message: "",
+		},
+		{
+			label:    "standard error",
+			err:      errors.New("dial tcp: connection refused"),
+			code:     codes.Unknown,
+			message:  "internal error",
+			metadata: map[string]string{},
+		},
+		{
+			label: "registered type with request id and tags",
+			err: serrors.Builder(errors.New("user 42 not in table")).
+				Type("grpcxNotFound").
+				RequestID("req-1").
+				PublicMessage("user not found").
+				AddTagInt("user_id", 42).
+				AddTagSensitive("email", "alice@example.com").
+				Build(),
+			code:     codes.NotFound,
+			message:  "user not found",
+			reason:   "grpcxNotFound",
+			metadata: map[string]string{"request_id": "req-1", "user_id": "42", "email": serrors.RedactedStr},
+		},
+		{
+			label:    "wrapped status error",
+			err:      serrors.Wrap(status.Error(codes.PermissionDenied, "denied"), "call upstream"),
+			code:     codes.PermissionDenied,
+			message:  "internal error",
+			metadata: map[string]string{},
+		},
+		{
+			label:    "context error",
+			err:      fmt.Errorf("query: %w", context.DeadlineExceeded),
+			code:     codes.DeadlineExceeded,
+			message:  "internal error",
+			metadata: map[string]string{},
+		},
+		{
+			label:    "code mapper",
+			err:      errors.New("boom"),
+			opts:     &Options{CodeMapper: func(err error) codes.Code { return codes.Unavailable }},
+			code:     codes.Unavailable,
+			message:  "internal error",
+			metadata: map[string]string{},
+		},
+		{
+			label:        "debug info",
+			err:          serrors.New("secret internal message"),
+			opts:         &Options{Debug: true},
+			code:         codes.Unknown,
+			message:      "internal error",
+			metadata:     map[string]string{},
+			hasDebugInfo: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.label, func(t *testing.T) {
+			st := ToStatus(tc.err, tc.opts)
+			if st.Code() != tc.code {
+				t.Errorf("expected code %v, got %v", tc.code, st.Code())
+			}
+			if st.Message() != tc.message {
+				t.Errorf("expected message %q, got %q", tc.message, st.Message())
+			}
+			var info *errdetails.ErrorInfo
+			var debug *errdetails.DebugInfo
+			for _, detail := range st.Details() {
+				switch d := detail.(type) {
+				case *errdetails.ErrorInfo:
+					info = d
+				case *errdetails.DebugInfo:
+					debug = d
+				}
+			}
+			if tc.metadata == nil {
+				if info != nil {
+					t.Errorf("expected no ErrorInfo, got %v", info)
+				}
+				return
+			}
+			if info == nil {
+				t.Fatalf("expected ErrorInfo")
+			}
+			if info.Reason != tc.reason || info.Domain != DefaultDomain {
+				t.Errorf("unexpected reason or domain %s %s", info.Reason, info.Domain)
+			}
+			if fmt.Sprint(info.Metadata) != fmt.Sprint(tc.metadata) {
+				t.Errorf("expected metadata %v, got %v", tc.metadata, info.Metadata)
+			}
+			if (debug != nil) != tc.hasDebugInfo {
+				t.Fatalf("expected DebugInfo %v, got %v", tc.hasDebugInfo, debug)
+			}
+			if debug != nil && (debug.Detail != "[Type: none] secret internal message" || len(debug.StackEntries) == 0) {
+				t.Errorf("unexpected DebugInfo %v", debug)
+			}
+		})
+	}
+}
+
+func TestToStatus_WrappedError(t *testing.T) {
+	fe := serrors.Builder(errors.New("user 42 not in table")).
+		StackTrace().
+		RequestID("req-1").
+		AddTagInt("user_id", 42).
+		Build()
+	st := ToStatus(fmt.Errorf("rpc: %w", fe), &Options{Debug: true})
+
+	var info *errdetails.ErrorInfo
+	var debug *errdetails.DebugInfo
+	for _, detail := range st.Details() {
+		switch d := detail.(type) {
+		case *errdetails.ErrorInfo:
+			info = d
+		case *errdetails.DebugInfo:
+			debug = d
+		}
+	}
+	if info == nil || info.Metadata["user_id"] != "42" || info.Metadata[RequestIDMetadataKey] != "req-1" {
+		t.Errorf("expected tags of the wrapped error in metadata, got %v", info)
+	}
+	if debug == nil || len(debug.StackEntries) == 0 || !strings.Contains(debug.StackEntries[0], "grpcx.TestToStatus_WrappedError") {
+		t.Errorf("expected stack trace of the wrapped error in DebugInfo, got %v", debug)
+	}
+}
+
+func TestToStatus_StatusError(t *testing.T) {
+	original := status.New(codes.AlreadyExists, "exists")
+	if got := ToStatus(original.Err(), nil); got.Code() != codes.AlreadyExists || got.Message() != "exists" {
+		t.Errorf("expected status error to be returned as it is, got %v", got)
+	}
+}
+
+func TestFromStatus(t *testing.T) {
+	if FromStatus(nil) != nil || FromStatus(status.New(codes.OK, "")) != nil {
+		t.Errorf("expected nil for nil or OK status")
+	}
+
+	serrors.RegisterType("grpcxConflict", serrors.TypeInfo{GRPCCode: int(codes.Aborted)})
+	defer serrors.UnregisterType("grpcxConflict")
+
+	sent := serrors.Builder(errors.New("version mismatch")).
+		Type("grpcxConflict").
+		RequestID("req-9").
+		PublicMessage("conflict").
+		AddTagString("order", "o-1").
+		Build()
+	got := FromStatus(ToStatus(sent, nil))
+
+	if got.Type() != "grpcxConflict" || got.RequestID() != "req-9" {
+		t.Errorf("unexpected type or request id %s %s", got.Type(), got.RequestID())
+	}
+	if serrors.PublicMessage(got) != "conflict" {
+		t.Errorf("expected public message conflict, got %s", serrors.PublicMessage(got))
+	}
+	if st, ok := status.FromError(got); !ok || st.Code() != codes.Aborted {
+		t.Errorf("expected status error to be wrapped, got %v", st)
+	}
+	tags := got.(interface{ Tags() serrors.Tags }).Tags()
+	if v, ok := tags.GetValue("order"); !ok || v != serrors.StringTagValue("o-1") {
+		t.Errorf("expected tag order, got %v", v)
+	}
+	if again := ToStatus(got, nil); again.Code() != codes.Aborted {
+		t.Errorf("expected the same code to be returned, got %v", again.Code())
+	}
+}
@@ -0,0 +1,169 @@
+// Package httpx provides net/http middleware and helpers writing structured errors as responses
+package httpx
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"io"
+	"net"
+	"net/http"
+
+	serrors "github.com/hinoguma/go-structured-error"
+)
+
+// TypePanic is serrors.TypePanic, the type of errors recovered from panics by Middleware
+const TypePanic = serrors.TypePanic
+
+const DefaultRequestIDHeader string = "X-Request-ID"
+
+// Options configures Middleware and WriteError
+type Options struct {
+	// RequestIDHeader is the header the request id is read from and written to.
+	// if it is empty, DefaultRequestIDHeader is used.
+	RequestIDHeader string
+	// NewRequestID generates a request id if the request has none.
+	// if it is nil, a random 32 hex characters id is generated.
+	NewRequestID func() string
+	// StatusMapper chooses the status of responses written by WriteError.
+	// if it is nil, serrors.HTTPStatus is used.
+	StatusMapper StatusMapper
+	// Debug includes the JSON of the error with internal details in responses.
+	// never enable it in production.
+	Debug bool
+	// Problem makes WriteError write Problem Details by WriteProblem with these options.
+	// if ProblemOptions.StatusMapper is nil, StatusMapper above is used.
+	Problem *ProblemOptions
+	// Wire makes WriteError and WriteProblem write the wire encoding of errors to the response header,
+	// so callers using Transport get the errors. enable it only for internal services.
+	Wire *WireOptions
+	// OnError is called with every error written by WriteError, e.g. for logging.
+	OnError func(r *http.Request, err error)
+}
+
+type optionsContextKey struct{}
+
+// Middleware wraps next with default options
+func Middleware(next http.Handler) http.Handler {
+	return NewMiddleware(nil)(next)
+}
+
+// NewMiddleware returns middleware which
+//   - injects the request id of the request header, or a new one, into the context by serrors.ContextWithRequestID()
+//   - recovers panics into StructuredError of TypePanic with stack trace and writes it by WriteError
+//     if the response is already started, it calls Options.OnError and panics with http.ErrAbortHandler to abort the response
+//   - makes WriteError use opts
+//
+// if opts is nil, default options are used.
+func NewMiddleware(opts *Options) func(http.Handler) http.Handler {
+	o := Options{}
+	if opts != nil {
+		o = *opts
+	}
+	if o.RequestIDHeader == "" {
+		o.RequestIDHeader = DefaultRequestIDHeader
+	}
+	if o.NewRequestID == nil {
+		o.NewRequestID = newRequestID
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requestID := r.Header.Get(o.RequestIDHeader)
+			if requestID == "" {
+				requestID = o.NewRequestID()
+			}
+			w.Header().Set(o.RequestIDHeader, requestID)
+
+			ctx := serrors.ContextWithRequestID(r.Context(), requestID)
+			ctx = context.WithValue(ctx, optionsContextKey{}, o)
+			r = r.WithContext(ctx)
+
+			rw := &responseWriter{ResponseWriter: w}
+			defer func() {
+				v := recover()
+				if v == nil {
+					return
+				}
+				if v == http.ErrAbortHandler {
+					panic(v)
+				}
+				err := serrors.RecoverValueForClients(v)
+				if rw.wroteHeader {
+					// the response is already started and cannot be replaced,
+					// so abort it to let the client know it is incomplete
+					if o.OnError != nil {
+						o.OnError(r, err)
+					}
+					panic(http.ErrAbortHandler)
+				}
+				WriteError(rw, r, err)
+			}()
+			next.ServeHTTP(rw, r)
+		})
+	}
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b)
+}
+
+// optionsFrom returns options set by Middleware, or default options
+func optionsFrom(r *http.Request) Options {
+	if r != nil {
+		if o, ok := r.Context().Value(optionsContextKey{}).(Options); ok {
+			return o
+		}
+	}
+	return Options{RequestIDHeader: DefaultRequestIDHeader}
+}
+
+// responseWriter records whether the header is written to know if an error response can be written
+// It implements http.Flusher, http.Hijacker and io.ReaderFrom so handlers can assert them as with the original ResponseWriter.
+type responseWriter struct {
+	http.ResponseWriter
+	wroteHeader bool
+}
+
+func (w *responseWriter) WriteHeader(status int) {
+	w.wroteHeader = true
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+	return w.ResponseWriter.Write(b)
+}
+
+// Unwrap makes http.ResponseController work with the original ResponseWriter
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// Flush flushes the original ResponseWriter if it supports flushing
+func (w *responseWriter) Flush() {
+	w.wroteHeader = true
+	_ = http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// Hijack hijacks the original ResponseWriter. it returns an error wrapping http.ErrNotSupported if it is not supported.
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, brw, err := http.NewResponseController(w.ResponseWriter).Hijack()
+	if err == nil {
+		w.wroteHeader = true
+	}
+	return conn, brw, err
+}
+
+// ReadFrom keeps the sendfile optimization of the original ResponseWriter
+func (w *responseWriter) ReadFrom(r io.Reader) (int64, error) {
+	w.wroteHeader = true
+	if rf, ok := w.ResponseWriter.(io.ReaderFrom); ok {
+		return rf.ReadFrom(r)
+	}
+	return io.Copy(w.ResponseWriter, r)
+}
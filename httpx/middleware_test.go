@@ -0,0 +1,230 @@
+package httpx
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	serrors "github.com/hinoguma/go-structured-error"
+)
+
+func TestMiddleware_RequestID(t *testing.T) {
+	var got string
+	handler := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = serrors.RequestIDFromContext(r.Context())
+	}))
+
+	testCases := []struct {
+		label    string
+		header   string
+		expected func(id string) bool
+	}{
+		{
+			label:    "request id of header",
+			header:   "req-from-client",
+			expected: func(id string) bool { return id == "req-from-client" },
+		},
+		{
+			label:    "new request id",
+			header:   "",
+			expected: func(id string) bool { return len(id) == 32 },
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.label, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tc.header != "" {
+				req.Header.Set(DefaultRequestIDHeader, tc.header)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			if !tc.expected(got) {
+				t.Errorf("unexpected request id in context: %q", got)
+			}
+			if rec.Header().Get(DefaultRequestIDHeader) != got {
+				t.Errorf("expected response header %q, got %q", got, rec.Header().Get(DefaultRequestIDHeader))
+			}
+		})
+	}
+}
+
+func panickingHandler(v any) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic(v)
+	})
+}
+
+func TestMiddleware_Recover(t *testing.T) {
+	var logged error
+	mw := NewMiddleware(&Options{
+		NewRequestID: func() string { return "req-1" },
+		OnError:      func(r *http.Request, err error) { logged = err },
+	})
+
+	testCases := []struct {
+		label   string
+		v       any
+		message string
+	}{
+		{label: "panic with string", v: "something broke", message: "panic: something broke"},
+		{label: "panic with error", v: errors.New("broken error"), message: "broken error"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.label, func(t *testing.T) {
+			logged = nil
+			rec := httptest.NewRecorder()
+			mw(panickingHandler(tc.v)).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+			if rec.Code != http.StatusInternalServerError {
+				t.Errorf("expected status 500, got %d", rec.Code)
+			}
+			expected := `{"type":"panic","message":"internal error","request_id":"req-1"}` + "\n"
+			if rec.Body.String() != expected {
+				t.Errorf("expected %s, got %s", expected, rec.Body.String())
+			}
+			if !serrors.IsType(logged, TypePanic) {
+				t.Fatalf("expected panic error to be passed to OnError, got %v", logged)
+			}
+			if !strings.HasSuffix(logged.Error(), tc.message) {
+				t.Errorf("expected message %s, got %s", tc.message, logged.Error())
+			}
+			st := logged.(serrors.SError).StackTrace()
+			if len(st) == 0 || !strings.HasPrefix(st[0].Function, "github.com/hinoguma/go-structured-error/httpx.panickingHandler") {
+				t.Errorf("expected stack trace to start at the panicking function, got %v", st)
+			}
+		})
+	}
+}
+
+func TestMiddleware_RecoverAfterWrite(t *testing.T) {
+	var logged error
+	mw := NewMiddleware(&Options{OnError: func(r *http.Request, err error) { logged = err }})
+	rec := httptest.NewRecorder()
+	func() {
+		defer func() {
+			if v := recover(); v != http.ErrAbortHandler {
+				t.Errorf("expected http.ErrAbortHandler to abort the started response, got %v", v)
+			}
+		}()
+		mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusAccepted)
+			_, _ = w.Write([]byte("partial"))
+			panic("after write")
+		})).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	}()
+
+	if rec.Code != http.StatusAccepted || rec.Body.String() != "partial" {
+		t.Errorf("expected the started response not to be replaced, got %d %s", rec.Code, rec.Body.String())
+	}
+	if !serrors.IsType(logged, TypePanic) {
+		t.Errorf("expected panic error to be passed to OnError, got %v", logged)
+	}
+}
+
+func TestMiddleware_ResponseWriterInterfaces(t *testing.T) {
+	testCases := []struct {
+		label          string
+		handler        http.HandlerFunc
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			label: "flusher",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte("flushed"))
+				w.(http.Flusher).Flush()
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   "flushed",
+		},
+		{
+			label: "reader from",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.(io.ReaderFrom).ReadFrom(strings.NewReader("copied"))
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   "copied",
+		},
+		{
+			label: "hijacker",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				conn, brw, err := w.(http.Hijacker).Hijack()
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+					return
+				}
+				defer conn.Close()
+				_, _ = brw.WriteString("HTTP/1.1 418 I'm a teapot\r\nContent-Length: 8\r\nConnection: close\r\n\r\nhijacked")
+				_ = brw.Flush()
+			},
+			expectedStatus: http.StatusTeapot,
+			expectedBody:   "hijacked",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.label, func(t *testing.T) {
+			server := httptest.NewServer(Middleware(tc.handler))
+			defer server.Close()
+
+			resp, err := http.Get(server.URL)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			defer resp.Body.Close()
+			body, _ := io.ReadAll(resp.Body)
+			if resp.StatusCode != tc.expectedStatus || string(body) != tc.expectedBody {
+				t.Errorf("expected %d %s, got %d %s", tc.expectedStatus, tc.expectedBody, resp.StatusCode, body)
+			}
+		})
+	}
+}
+
+func TestMiddleware_HijackNotSupported(t *testing.T) {
+	rec := httptest.NewRecorder()
+	Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, _, err := w.(http.Hijacker).Hijack(); !errors.Is(err, http.ErrNotSupported) {
+			t.Errorf("expected http.ErrNotSupported, got %v", err)
+		}
+		WriteError(w, r, errors.New("not hijacked"))
+	})).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("expected error response after failed hijack, got %d", rec.Code)
+	}
+}
+
+func TestMiddleware_AbortHandler(t *testing.T) {
+	defer func() {
+		if v := recover(); v != http.ErrAbortHandler {
+			t.Errorf("expected http.ErrAbortHandler to be re-panicked, got %v", v)
+		}
+	}()
+	Middleware(panickingHandler(http.ErrAbortHandler)).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+}
+
+func TestMiddleware_Debug(t *testing.T) {
+	mw := NewMiddleware(&Options{Debug: true, NewRequestID: func() string { return "req-1" }})
+	rec := httptest.NewRecorder()
+	mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		WriteError(w, r, errors.New("db password is wrong"))
+	})).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	var body ErrorResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	restored, err := serrors.FromJSON(body.Error)
+	if err != nil {
+		t.Fatalf("expected JSON of the error in debug mode: %v", err)
+	}
+	if restored.Unwrap().Error() != "db password is wrong" || restored.RequestID() != "req-1" {
+		t.Errorf("unexpected error in debug response: %s", body.Error)
+	}
+}
@@ -0,0 +1,253 @@
+package httpx
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	serrors "github.com/hinoguma/go-structured-error"
+)
+
+// ProblemContentType is the media type of Problem Details defined in RFC 9457
+const ProblemContentType string = "application/problem+json"
+
+// ProblemTypeBlank is the type of problems having no type URI
+const ProblemTypeBlank string = "about:blank"
+
+// tag keys of StructuredError restored by ParseProblem
+const (
+	ProblemTypeTagKey   string = "problem_type"
+	ProblemTitleTagKey  string = "problem_title"
+	ProblemStatusTagKey string = "problem_status"
+)
+
+// Problem is Problem Details for HTTP APIs defined in RFC 9457 (obsoletes RFC 7807)
+type Problem struct {
+	Type     string
+	Title    string
+	Status   int
+	Detail   string
+	Instance string
+	// Extensions are additional members written at the top level of the JSON object in order
+	Extensions serrors.Tags
+}
+
+// ProblemOptions configures ProblemDetails and ParseProblem
+type ProblemOptions struct {
+	// TypeBaseURI makes the type URI by appending ErrorType, e.g. "https://example.com/problems/" + "db.timeout".
+	// if it is empty, serrors.DocURL(err) is used, and ProblemTypeBlank if it is not registered either.
+	TypeBaseURI string
+	// StatusMapper chooses the status. if it is nil, serrors.HTTPStatus is used.
+	StatusMapper StatusMapper
+	// ExtensionTags are keys of tags written as extension members.
+	// tags not listed here are never written. SecretTagValue is written as serrors.RedactedStr.
+	ExtensionTags []string
+}
+
+// ProblemDetails renders err into Problem
+//   - type is the URI of the error type by ProblemOptions.TypeBaseURI or serrors.DocURL()
+//   - title is the status text and detail is serrors.PublicMessage(err), so internal messages are not exposed
+//   - instance is the request id of err
+//   - extension members are tags listed in ProblemOptions.ExtensionTags
+//
+// if opts is nil, default options are used.
+func ProblemDetails(err error, opts *ProblemOptions) Problem {
+	o := ProblemOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	status := serrors.HTTPStatus(err)
+	if o.StatusMapper != nil {
+		status = o.StatusMapper(err)
+	}
+
+	p := Problem{
+		Type:       ProblemTypeBlank,
+		Title:      http.StatusText(status),
+		Status:     status,
+		Detail:     serrors.PublicMessage(err),
+		Instance:   serrors.OutermostRequestID(err),
+		Extensions: serrors.NewTags(),
+	}
+	if t := serrors.OutermostType(err); o.TypeBaseURI != "" && t != serrors.ErrorTypeNone {
+		p.Type = o.TypeBaseURI + t.String()
+	} else if docURL := serrors.DocURL(err); docURL != "" {
+		p.Type = docURL
+	}
+	redactor := serrors.DefaultRedactor()
+	for _, key := range o.ExtensionTags {
+		value, ok := tagValue(err, key)
+		if !ok {
+			continue
+		}
+		if redactor != nil {
+			value = redactor.Redact(key, value)
+		}
+		p.Extensions.SetValueSafe(key, value)
+	}
+	return p
+}
+
+// WriteProblem writes err as Problem with ProblemContentType
+// the request id injected by Middleware is used as instance if err has none.
+// if err is nil, nothing is written.
+func WriteProblem(w http.ResponseWriter, r *http.Request, err error, opts *ProblemOptions) {
+	if err == nil {
+		return
+	}
+	if r != nil {
+		err = serrors.CopyWithContext(r.Context(), err)
+	}
+	p := ProblemDetails(err, opts)
+	w.Header().Set("Content-Type", ProblemContentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	setWireHeader(w, r, err)
+	w.WriteHeader(p.Status)
+	_ = json.NewEncoder(w).Encode(p)
+
+	if o := optionsFrom(r); o.OnError != nil {
+		o.OnError(r, err)
+	}
+}
+
+var problemMembers = []string{"type", "title", "status", "detail", "instance"}
+
+func (p Problem) MarshalJSON() ([]byte, error) {
+	members := serrors.NewTags()
+	if p.Type != "" {
+		members.SetValueSafe("type", serrors.StringTagValue(p.Type))
+	}
+	if p.Title != "" {
+		members.SetValueSafe("title", serrors.StringTagValue(p.Title))
+	}
+	if p.Status != 0 {
+		members.SetValueSafe("status", serrors.IntTagValue(p.Status))
+	}
+	if p.Detail != "" {
+		members.SetValueSafe("detail", serrors.StringTagValue(p.Detail))
+	}
+	if p.Instance != "" {
+		members.SetValueSafe("instance", serrors.StringTagValue(p.Instance))
+	}
+	for _, tag := range p.Extensions.List() {
+		if isProblemMember(tag.Key) {
+			// extensions must not override members defined by RFC 9457
+			continue
+		}
+		members.SetValueSafe(tag.Key, tag.Value)
+	}
+	return json.Marshal(members)
+}
+
+func (p *Problem) UnmarshalJSON(data []byte) error {
+	var members serrors.Tags
+	if err := json.Unmarshal(data, &members); err != nil {
+		return err
+	}
+	restored := Problem{Extensions: serrors.NewTags()}
+	for _, tag := range members.List() {
+		value := tag.Value
+		switch tag.Key {
+		case "type":
+			restored.Type = stringMember(value)
+		case "title":
+			restored.Title = stringMember(value)
+		case "status":
+			if v, ok := value.(serrors.IntTagValue); ok {
+				restored.Status = int(v)
+			}
+		case "detail":
+			restored.Detail = stringMember(value)
+		case "instance":
+			restored.Instance = stringMember(value)
+		default:
+			restored.Extensions.SetValueSafe(tag.Key, value)
+		}
+	}
+	*p = restored
+	return nil
+}
+
+// ParseProblem parses a received problem document into StructuredError for client-side use
+//   - type is the rest of the type URI after ProblemOptions.TypeBaseURI, or none
+//   - message and public message are detail, or title if detail is empty
+//   - request id is instance
+//   - type URI, title, status and extension members are restored as tags
+//
+// if opts is nil, default options are used.
+func ParseProblem(data []byte, opts *ProblemOptions) (*serrors.StructuredError, error) {
+	o := ProblemOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	if string(bytes.TrimSpace(data)) == "null" {
+		return nil, errors.New("httpx: problem must be a JSON object")
+	}
+	var p Problem
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("httpx: invalid problem: %w", err)
+	}
+
+	message := p.Detail
+	if message == "" {
+		message = p.Title
+	}
+	if message == "" {
+		message = http.StatusText(p.Status)
+	}
+	fe := serrors.NewRawStructuredError(errors.New(message))
+	_ = fe.SetPublicMessage(message)
+	_ = fe.SetRequestID(p.Instance)
+	if o.TypeBaseURI != "" && strings.HasPrefix(p.Type, o.TypeBaseURI) {
+		_ = fe.SetType(serrors.ErrorType(strings.TrimPrefix(p.Type, o.TypeBaseURI)))
+	}
+	if p.Type != "" && p.Type != ProblemTypeBlank {
+		_ = fe.AddTagSafe(ProblemTypeTagKey, serrors.StringTagValue(p.Type))
+	}
+	if p.Title != "" {
+		_ = fe.AddTagSafe(ProblemTitleTagKey, serrors.StringTagValue(p.Title))
+	}
+	if p.Status != 0 {
+		_ = fe.AddTagSafe(ProblemStatusTagKey, serrors.IntTagValue(p.Status))
+	}
+	for _, tag := range p.Extensions.List() {
+		_ = fe.AddTagSafe(tag.Key, tag.Value)
+	}
+	return fe, nil
+}
+
+// tagValue returns the value of key in the outermost error having it
+func tagValue(err error, key string) (serrors.TagValue, bool) {
+	var found serrors.TagValue
+	ok := serrors.WalkChain(err, func(e error) bool {
+		te, ok := e.(interface{ Tags() serrors.Tags })
+		if !ok {
+			return false
+		}
+		value, ok := te.Tags().GetValue(key)
+		if ok {
+			found = value
+		}
+		return ok
+	})
+	return found, ok
+}
+
+func isProblemMember(key string) bool {
+	for _, member := range problemMembers {
+		if key == member {
+			return true
+		}
+	}
+	return false
+}
+
+func stringMember(value serrors.TagValue) string {
+	if v, ok := value.(serrors.StringTagValue); ok {
+		return string(v)
+	}
+	return ""
+}
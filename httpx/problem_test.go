@@ -0,0 +1,167 @@
+package httpx
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	serrors "github.com/hinoguma/go-structured-error"
+)
+
+func newProblemTestError() error {
+	return serrors.Builder(errors.New("select * from orders: deadlock")).
+		Type("order.conflict").
+		RequestID("req-1").
+		PublicMessage("the order was updated by someone else").
+		AddTagInt("order_id", 42).
+		AddTagString("sql", "select * from orders").
+		AddTagSensitive("customer_email", "alice@example.com").
+		Build()
+}
+
+func TestProblemDetails(t *testing.T) {
+	serrors.RegisterType("order.conflict", serrors.TypeInfo{
+		HTTPStatus: http.StatusConflict,
+		DocURL:     "https://example.com/docs/order-conflict",
+	})
+	defer serrors.UnregisterType("order.conflict")
+
+	testCases := []struct {
+		label    string
+		err      error
+		opts     *ProblemOptions
+		expected string
+	}{
+		{
+			label:    "standard error",
+			err:      errors.New("dial tcp: connection refused"),
+			opts:     nil,
+			expected: `{"type":"about:blank","title":"Internal Server Error","status":500,"detail":"internal error"}`,
+		},
+		{
+			label:    "type uri by doc url",
+			err:      newProblemTestError(),
+			opts:     nil,
+			expected: `{"type":"https://example.com/docs/order-conflict","title":"Conflict","status":409,"detail":"the order was updated by someone else","instance":"req-1"}`,
+		},
+		{
+			label: "type uri by base uri and whitelisted tags",
+			err:   newProblemTestError(),
+			opts: &ProblemOptions{
+				TypeBaseURI:   "https://example.com/problems/",
+				ExtensionTags: []string{"order_id", "customer_email", "missing"},
+			},
+			expected: `{"type":"https://example.com/problems/order.conflict","title":"Conflict","status":409,"detail":"the order was updated by someone else","instance":"req-1","order_id":42,"customer_email":"[REDACTED]"}`,
+		},
+		{
+			label: "status mapper",
+			err:   newProblemTestError(),
+			opts: &ProblemOptions{
+				StatusMapper: func(err error) int { return http.StatusServiceUnavailable },
+			},
+			expected: `{"type":"https://example.com/docs/order-conflict","title":"Service Unavailable","status":503,"detail":"the order was updated by someone else","instance":"req-1"}`,
+		},
+		{
+			label: "extensions do not override members",
+			err:   serrors.With(errors.New("boom"), serrors.WithTag("status", "broken")),
+			opts: &ProblemOptions{
+				ExtensionTags: []string{"status"},
+			},
+			expected: `{"type":"about:blank","title":"Internal Server Error","status":500,"detail":"internal error"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.label, func(t *testing.T) {
+			got, err := json.Marshal(ProblemDetails(tc.err, tc.opts))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(got) != tc.expected {
+				t.Errorf("expected %s, got %s", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestWriteProblem(t *testing.T) {
+	opts := &Options{
+		NewRequestID: func() string { return "req-mw" },
+		Problem:      &ProblemOptions{TypeBaseURI: "https://example.com/problems/"},
+		StatusMapper: TypeStatusMapper(map[serrors.ErrorType]int{"db": http.StatusServiceUnavailable}, nil),
+	}
+	rec := httptest.NewRecorder()
+	NewMiddleware(opts)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		WriteError(w, r, serrors.With(errors.New("timeout"), serrors.WithType("db.timeout")))
+	})).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected status 503, got %d", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != ProblemContentType {
+		t.Errorf("expected %s, got %s", ProblemContentType, ct)
+	}
+	expected := `{"type":"https://example.com/problems/db.timeout","title":"Service Unavailable","status":503,"detail":"internal error","instance":"req-mw"}` + "\n"
+	if rec.Body.String() != expected {
+		t.Errorf("expected %s, got %s", expected, rec.Body.String())
+	}
+}
+
+func TestParseProblem(t *testing.T) {
+	opts := &ProblemOptions{TypeBaseURI: "https://example.com/problems/"}
+
+	data := []byte(`{"type":"https://example.com/problems/order.conflict","title":"Conflict","status":409,"detail":"the order was updated by someone else","instance":"req-1","order_id":42,"retry":{"after":"1s"}}`)
+	got, err := ParseProblem(data, opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Type() != "order.conflict" {
+		t.Errorf("expected type order.conflict, got %s", got.Type())
+	}
+	if got.Unwrap().Error() != "the order was updated by someone else" || serrors.PublicMessage(got) != "the order was updated by someone else" {
+		t.Errorf("unexpected message %s", got.Error())
+	}
+	if got.RequestID() != "req-1" {
+		t.Errorf("expected request id req-1, got %s", got.RequestID())
+	}
+	expectedTags := `{"problem_type":"https://example.com/problems/order.conflict","problem_title":"Conflict","problem_status":409,"order_id":42,"retry":{"after":"1s"}}`
+	if tags := got.Tags(); tags.JsonValueString() != expectedTags {
+		t.Errorf("expected %s, got %s", expectedTags, tags.JsonValueString())
+	}
+
+	// the type URI not under TypeBaseURI is kept as tag only
+	got, err = ParseProblem([]byte(`{"type":"https://other.example.com/oops","title":"Bad Request","status":400}`), opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Type() != serrors.ErrorTypeNone || got.Unwrap().Error() != "Bad Request" {
+		t.Errorf("unexpected error %v", got)
+	}
+
+	for _, invalid := range []string{`null`, `[1]`, `{"status":`} {
+		if _, err := ParseProblem([]byte(invalid), opts); err == nil {
+			t.Errorf("expected error for %s", invalid)
+		}
+	}
+}
+
+func TestProblem_RoundTrip(t *testing.T) {
+	p := ProblemDetails(newProblemTestError(), &ProblemOptions{
+		TypeBaseURI:   "https://example.com/problems/",
+		ExtensionTags: []string{"order_id"},
+	})
+	data, err := json.Marshal(p)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var restored Problem
+	if err := json.Unmarshal(data, &restored); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	again, _ := json.Marshal(restored)
+	if string(again) != string(data) {
+		t.Errorf("expected %s, got %s", data, again)
+	}
+}
@@ -0,0 +1,107 @@
+package httpx
+
+import (
+	"encoding/base64"
+	"io"
+	"mime"
+	"net/http"
+
+	serrors "github.com/hinoguma/go-structured-error"
+)
+
+// DefaultWireHeader is the response header carrying serrors.EncodeWire() encoded by base64url without padding
+const DefaultWireHeader string = "X-Error-Wire"
+
+// WireContentType is the content type of response bodies which are serrors.EncodeWire() as they are
+const WireContentType string = "application/vnd.serrors.wire+json"
+
+// MaxWireBodySize is the maximum size of wire bodies read by Transport
+const MaxWireBodySize int64 = 1 << 20
+
+// WireOptions makes WriteError and WriteProblem write the wire encoding of errors to the response header
+type WireOptions struct {
+	// Header is the response header. if it is empty, DefaultWireHeader is used.
+	Header string
+	// Encoding configures serrors.EncodeWire(). Origin should be the name of the service.
+	Encoding serrors.WireOptions
+}
+
+// setWireHeader sets the wire encoding of err to the header if Options.Wire is set
+func setWireHeader(w http.ResponseWriter, r *http.Request, err error) {
+	o := optionsFrom(r)
+	if o.Wire == nil {
+		return
+	}
+	header := o.Wire.Header
+	if header == "" {
+		header = DefaultWireHeader
+	}
+	w.Header().Set(header, base64.RawURLEncoding.EncodeToString(serrors.EncodeWire(err, &o.Wire.Encoding)))
+}
+
+// WriteWire writes the wire encoding of err as the body with WireContentType and status
+// it is for internal APIs called through Transport. public APIs should use WriteError.
+// if err is nil, nothing is written.
+func WriteWire(w http.ResponseWriter, err error, status int, opts *serrors.WireOptions) {
+	if err == nil {
+		return
+	}
+	w.Header().Set("Content-Type", WireContentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+	_, _ = w.Write(serrors.EncodeWire(err, opts))
+}
+
+// Transport is http.RoundTripper which turns error responses carrying the wire encoding into errors
+// Responses with status 400 or more and the wire header, or the body of WireContentType, are closed
+// and RoundTrip returns the decoded error, so errors of the called service keep their types and tags.
+// http.Client wraps it by *url.Error, and serrors.IsType() and serrors.IsRemote() look into it.
+// other responses, including ones which cannot be decoded, are returned as they are.
+type Transport struct {
+	// Base is the underlying RoundTripper. if it is nil, http.DefaultTransport is used.
+	Base http.RoundTripper
+	// Header is the response header to decode. if it is empty, DefaultWireHeader is used.
+	Header string
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	resp, err := base.RoundTrip(req)
+	if err != nil || resp.StatusCode < http.StatusBadRequest {
+		return resp, err
+	}
+
+	header := t.Header
+	if header == "" {
+		header = DefaultWireHeader
+	}
+	if v := resp.Header.Get(header); v != "" {
+		data, decodeErr := base64.RawURLEncoding.DecodeString(v)
+		if decodeErr != nil {
+			return resp, nil
+		}
+		fe, decodeErr := serrors.DecodeWire(data)
+		if decodeErr != nil {
+			return resp, nil
+		}
+		_ = resp.Body.Close()
+		return nil, fe
+	}
+
+	if mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type")); mediaType == WireContentType {
+		data, readErr := io.ReadAll(io.LimitReader(resp.Body, MaxWireBodySize))
+		_ = resp.Body.Close()
+		if readErr != nil {
+			return nil, readErr
+		}
+		fe, decodeErr := serrors.DecodeWire(data)
+		if decodeErr != nil {
+			return nil, decodeErr
+		}
+		return nil, fe
+	}
+	return resp, nil
+}
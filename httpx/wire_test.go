@@ -0,0 +1,121 @@
+package httpx
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	serrors "github.com/hinoguma/go-structured-error"
+)
+
+func TestTransport(t *testing.T) {
+	notFound := func() error {
+		return serrors.Builder(errors.New("user 42 not in table users")).
+			Type("user.notFound").
+			AddTagInt("user_id", 42).
+			Build()
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/header", func(w http.ResponseWriter, r *http.Request) {
+		WriteError(w, r, fmt.Errorf("get user: %w", notFound()))
+	})
+	mux.HandleFunc("/problem", func(w http.ResponseWriter, r *http.Request) {
+		WriteProblem(w, r, notFound(), nil)
+	})
+	mux.HandleFunc("/body", func(w http.ResponseWriter, r *http.Request) {
+		WriteWire(w, notFound(), http.StatusNotFound, &serrors.WireOptions{Origin: "user-service"})
+	})
+	mux.HandleFunc("/ok", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	})
+	mux.HandleFunc("/plain", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "bad request", http.StatusBadRequest)
+	})
+	mux.HandleFunc("/broken", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(DefaultWireHeader, "not base64!")
+		http.Error(w, "bad request", http.StatusBadRequest)
+	})
+	server := httptest.NewServer(NewMiddleware(&Options{
+		NewRequestID: func() string { return "req-1" },
+		Wire:         &WireOptions{Encoding: serrors.WireOptions{Origin: "user-service"}},
+	})(mux))
+	defer server.Close()
+
+	client := &http.Client{Transport: &Transport{}}
+
+	for _, path := range []string{"/header", "/problem", "/body"} {
+		t.Run(path, func(t *testing.T) {
+			resp, err := client.Get(server.URL + path)
+			if err == nil {
+				_ = resp.Body.Close()
+				t.Fatalf("expected error, got status %d", resp.StatusCode)
+			}
+			if !serrors.IsType(err, "user.notFound") {
+				t.Errorf("expected user.notFound, got %v", err)
+			}
+			if serrors.RemoteOrigin(err) != "user-service" {
+				t.Errorf("expected origin user-service, got %q", serrors.RemoteOrigin(err))
+			}
+			fe := findType(err, "user.notFound")
+			if fe == nil {
+				t.Fatalf("expected StructuredError in %v", err)
+			}
+			if v, ok := fe.Tags().GetValue("user_id"); !ok || v.String() != "42" {
+				t.Errorf("expected user_id tag, got %v", fe.Tags())
+			}
+			if path != "/body" && serrors.OutermostRequestID(err) != "req-1" {
+				t.Errorf("expected request id req-1, got %q", serrors.OutermostRequestID(err))
+			}
+		})
+	}
+
+	testCases := []struct {
+		label          string
+		path           string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{label: "success", path: "/ok", expectedStatus: http.StatusOK, expectedBody: "ok"},
+		{label: "error without wire encoding", path: "/plain", expectedStatus: http.StatusBadRequest, expectedBody: "bad request"},
+		{label: "broken wire header", path: "/broken", expectedStatus: http.StatusBadRequest, expectedBody: "bad request"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.label, func(t *testing.T) {
+			resp, err := client.Get(server.URL + tc.path)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			defer resp.Body.Close()
+			body, _ := io.ReadAll(resp.Body)
+			if resp.StatusCode != tc.expectedStatus || strings.TrimSpace(string(body)) != tc.expectedBody {
+				t.Errorf("expected %d %s, got %d %s", tc.expectedStatus, tc.expectedBody, resp.StatusCode, body)
+			}
+		})
+	}
+}
+
+func TestWriteError_NoWireHeaderByDefault(t *testing.T) {
+	w := httptest.NewRecorder()
+	WriteError(w, httptest.NewRequest(http.MethodGet, "/", nil), errors.New("boom"))
+	if v := w.Header().Get(DefaultWireHeader); v != "" {
+		t.Errorf("expected no wire header, got %s", v)
+	}
+}
+
+// findType returns the StructuredError of t in the chain of err
+func findType(err error, t serrors.ErrorType) *serrors.StructuredError {
+	var found *serrors.StructuredError
+	serrors.WalkChain(err, func(e error) bool {
+		fe, ok := e.(*serrors.StructuredError)
+		if ok && fe.Type() == t {
+			found = fe
+		}
+		return ok && fe.Type() == t
+	})
+	return found
+}
@@ -0,0 +1,99 @@
+package httpx
+
+import (
+	"encoding/json"
+	"net/http"
+
+	serrors "github.com/hinoguma/go-structured-error"
+)
+
+// StatusMapper returns the HTTP status of err
+type StatusMapper func(err error) int
+
+// TypeStatusMapper maps the outermost error type found in statuses to the status
+// if no type is found, fallback is used. if fallback is nil, serrors.HTTPStatus is used.
+// types are matched with their children, e.g. "db" matches "db.timeout".
+func TypeStatusMapper(statuses map[serrors.ErrorType]int, fallback StatusMapper) StatusMapper {
+	if fallback == nil {
+		fallback = serrors.HTTPStatus
+	}
+	return func(err error) int {
+		status := 0
+		serrors.WalkChain(err, func(e error) bool {
+			ht, ok := e.(serrors.HasType)
+			if !ok || ht.Type() == serrors.ErrorTypeNone {
+				return false
+			}
+			t := ht.Type()
+			if s, ok := statuses[t]; ok {
+				status = s
+				return true
+			}
+			for _, ancestor := range t.Ancestors() {
+				if s, ok := statuses[ancestor]; ok {
+					status = s
+					return true
+				}
+			}
+			return false
+		})
+		if status == 0 {
+			return fallback(err)
+		}
+		return status
+	}
+}
+
+// ErrorResponse is the JSON body written by WriteError
+type ErrorResponse struct {
+	Type      string `json:"type"`
+	Message   string `json:"message"`
+	RequestID string `json:"request_id,omitempty"`
+	// Error is the JSON of the error with internal details. it is set only if Options.Debug is true.
+	Error json.RawMessage `json:"error,omitempty"`
+}
+
+// WriteError writes err as JSON ErrorResponse with the status chosen by Options.StatusMapper
+// message is serrors.PublicMessage(err), so internal messages are not exposed unless Options.Debug is true.
+// options are taken from Middleware wrapping the handler, or default options are used.
+// if err is nil, nothing is written.
+func WriteError(w http.ResponseWriter, r *http.Request, err error) {
+	if err == nil {
+		return
+	}
+	o := optionsFrom(r)
+	if o.Problem != nil {
+		po := *o.Problem
+		if po.StatusMapper == nil {
+			po.StatusMapper = o.StatusMapper
+		}
+		WriteProblem(w, r, err, &po)
+		return
+	}
+	if r != nil {
+		err = serrors.CopyWithContext(r.Context(), err)
+	}
+	status := serrors.HTTPStatus(err)
+	if o.StatusMapper != nil {
+		status = o.StatusMapper(err)
+	}
+
+	body := ErrorResponse{
+		Type:      serrors.OutermostType(err).StringWithDefaultNone(),
+		Message:   serrors.PublicMessage(err),
+		RequestID: serrors.OutermostRequestID(err),
+	}
+	if o.Debug {
+		body.Error = json.RawMessage(serrors.ToJsonString(err))
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	setWireHeader(w, r, err)
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+
+	if o.OnError != nil {
+		o.OnError(r, err)
+	}
+}
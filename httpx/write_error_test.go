@@ -0,0 +1,152 @@
+package httpx
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+
+	serrors "github.com/hinoguma/go-structured-error"
+)
+
+func TestWriteError(t *testing.T) {
+	serrors.RegisterType("httpxNotFound", serrors.TypeInfo{HTTPStatus: http.StatusNotFound, PublicMessage: "not found"})
+	defer serrors.UnregisterType("httpxNotFound")
+
+	notFound := func() error {
+		return serrors.With(errors.New("user 42 not in table users"), serrors.WithType("httpxNotFound"))
+	}
+
+	testCases := []struct {
+		label          string
+		opts           *Options
+		err            error
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			label:          "standard error",
+			err:            errors.New("dial tcp: connection refused"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `{"type":"none","message":"internal error","request_id":"req-1"}`,
+		},
+		{
+			label:          "registered type",
+			err:            fmt.Errorf("handler: %w", notFound()),
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `{"type":"httpxNotFound","message":"not found","request_id":"req-1"}`,
+		},
+		{
+			label:          "public message",
+			err:            serrors.Builder(notFound()).PublicMessage("user not found").Build(),
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `{"type":"httpxNotFound","message":"user not found","request_id":"req-1"}`,
+		},
+		{
+			label: "type status mapper",
+			opts: &Options{StatusMapper: TypeStatusMapper(map[serrors.ErrorType]int{
+				"db": http.StatusServiceUnavailable,
+			}, nil)},
+			err:            serrors.With(errors.New("timeout"), serrors.WithType("db.timeout")),
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedBody:   `{"type":"db.timeout","message":"internal error","request_id":"req-1"}`,
+		},
+		{
+			label: "type status mapper falls back",
+			opts: &Options{StatusMapper: TypeStatusMapper(map[serrors.ErrorType]int{
+				"db": http.StatusServiceUnavailable,
+			}, nil)},
+			err:            notFound(),
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `{"type":"httpxNotFound","message":"not found","request_id":"req-1"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.label, func(t *testing.T) {
+			opts := &Options{NewRequestID: func() string { return "req-1" }}
+			if tc.opts != nil {
+				opts.StatusMapper = tc.opts.StatusMapper
+			}
+			rec := httptest.NewRecorder()
+			NewMiddleware(opts)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				WriteError(w, r, tc.err)
+			})).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+			if rec.Code != tc.expectedStatus {
+				t.Errorf("expected status %d, got %d", tc.expectedStatus, rec.Code)
+			}
+			if rec.Body.String() != tc.expectedBody+"\n" {
+				t.Errorf("expected %s, got %s", tc.expectedBody, rec.Body.String())
+			}
+			if ct := rec.Header().Get("Content-Type"); ct != "application/json; charset=utf-8" {
+				t.Errorf("unexpected Content-Type %s", ct)
+			}
+		})
+	}
+}
+
+func TestWriteError_WithoutMiddleware(t *testing.T) {
+	rec := httptest.NewRecorder()
+	WriteError(rec, httptest.NewRequest(http.MethodGet, "/", nil), serrors.With(errors.New("boom"), serrors.WithRequestID("req-err")))
+	expected := `{"type":"none","message":"internal error","request_id":"req-err"}` + "\n"
+	if rec.Code != http.StatusInternalServerError || rec.Body.String() != expected {
+		t.Errorf("unexpected response %d %s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	WriteError(rec, httptest.NewRequest(http.MethodGet, "/", nil), nil)
+	if rec.Body.Len() != 0 {
+		t.Errorf("expected nothing to be written for nil error, got %s", rec.Body.String())
+	}
+}
+
+func TestWriteError_SharedError(t *testing.T) {
+	sentinel := serrors.With(errors.New("not found"), serrors.WithType("notFound"))
+
+	testCases := []struct {
+		label string
+		opts  *Options
+		field string
+	}{
+		{label: "error response", opts: &Options{}, field: "request_id"},
+		{label: "problem details", opts: &Options{Problem: &ProblemOptions{}}, field: "instance"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.label, func(t *testing.T) {
+			handler := NewMiddleware(tc.opts)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				WriteError(w, r, sentinel)
+			}))
+
+			var wg sync.WaitGroup
+			for i := 0; i < 20; i++ {
+				wg.Add(1)
+				go func(id string) {
+					defer wg.Done()
+					req := httptest.NewRequest(http.MethodGet, "/", nil)
+					req.Header.Set(DefaultRequestIDHeader, id)
+					rec := httptest.NewRecorder()
+					handler.ServeHTTP(rec, req)
+
+					var body map[string]any
+					if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+						t.Errorf("unexpected error: %v", err)
+						return
+					}
+					if body[tc.field] != id {
+						t.Errorf("expected %s %s, got %v", tc.field, id, body[tc.field])
+					}
+				}("req-" + strconv.Itoa(i))
+			}
+			wg.Wait()
+
+			if fe := sentinel.(*serrors.StructuredError); fe.RequestID() != "" {
+				t.Errorf("expected the shared error not to be changed, got request id %s", fe.RequestID())
+			}
+		})
+	}
+}
@@ -0,0 +1,276 @@
+package serrors
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// FromJSON restores a StructuredError from the JSON produced by ToJsonString() or ErrorJsonPrinter.
+//
+// The original error value can not be restored, so the message is kept as errors.New(message).
+// Sub errors are always restored as *StructuredError.
+// Tag values are restored by ParseTagValue(). TimeTagValue is restored as StringTagValue.
+func FromJSON(data []byte) (*StructuredError, error) {
+	fe := NewRawStructuredError(nil)
+	if err := json.Unmarshal(data, fe); err != nil {
+		return nil, err
+	}
+	return fe, nil
+}
+
+// MarshalJSON makes StructuredError embeddable in any JSON document.
+// The output is the same as JsonString().
+func (e StructuredError) MarshalJSON() ([]byte, error) {
+	return []byte(e.JsonString()), nil
+}
+
+func (f ErrorJsonPrinter) MarshalJSON() ([]byte, error) {
+	return []byte(f.Print()), nil
+}
+
+func (tags Tags) MarshalJSON() ([]byte, error) {
+	return []byte(tags.JsonValueString()), nil
+}
+
+func (st StackTrace) MarshalJSON() ([]byte, error) {
+	return []byte(st.JsonValueString()), nil
+}
+
+func (v StringTagValue) MarshalJSON() ([]byte, error) {
+	return []byte(v.JsonValueString()), nil
+}
+
+func (v IntTagValue) MarshalJSON() ([]byte, error) {
+	return []byte(v.JsonValueString()), nil
+}
+
+func (v BoolTagValue) MarshalJSON() ([]byte, error) {
+	return []byte(v.JsonValueString()), nil
+}
+
+func (v FloatTagValue) MarshalJSON() ([]byte, error) {
+	return []byte(v.JsonValueString()), nil
+}
+
+func (v NilTagValue) MarshalJSON() ([]byte, error) {
+	return []byte(v.JsonValueString()), nil
+}
+
+func (v TimeTagValue) MarshalJSON() ([]byte, error) {
+	return []byte(v.JsonValueString()), nil
+}
+
+func (v DurationTagValue) MarshalJSON() ([]byte, error) {
+	return []byte(v.JsonValueString()), nil
+}
+
+func (v ObjectTagValue) MarshalJSON() ([]byte, error) {
+	return []byte(v.JsonValueString()), nil
+}
+
+func (v ArrayTagValue) MarshalJSON() ([]byte, error) {
+	return []byte(v.JsonValueString()), nil
+}
+
+func (v SecretTagValue) MarshalJSON() ([]byte, error) {
+	return []byte(v.JsonValueString()), nil
+}
+
+func (v JsonTagValue) MarshalJSON() ([]byte, error) {
+	return []byte(v.JsonValueString()), nil
+}
+
+// jsonStructuredError is the intermediate representation of the JSON format of StructuredError
+type jsonStructuredError struct {
+	Type          string             `json:"type"`
+	Message       *string            `json:"message"`
+	When          *string            `json:"when"`
+	RequestID     string             `json:"request_id"`
+	PublicMessage string             `json:"public_message"`
+	Origin        string             `json:"origin"`
+	Tags          Tags               `json:"tags"`
+	StackTrace    StackTrace         `json:"stacktrace"`
+	SubErrors     []*StructuredError `json:"sub_errors"`
+}
+
+func (e *StructuredError) UnmarshalJSON(data []byte) error {
+	if string(bytes.TrimSpace(data)) == "null" {
+		return nil
+	}
+	var raw jsonStructuredError
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	restored := NewRawStructuredError(nil)
+	if raw.Type != ErrorTypeNone.StringWithDefaultNone() {
+		restored.errorType = ErrorType(raw.Type)
+	}
+	if raw.Message != nil && *raw.Message != NoErrStr {
+		restored.err = errors.New(*raw.Message)
+	}
+	if raw.When != nil {
+		when, err := time.Parse(time.RFC3339, *raw.When)
+		if err != nil {
+			return fmt.Errorf("serrors: invalid when: %w", err)
+		}
+		restored.when = &when
+	}
+	restored.requestId = raw.RequestID
+	restored.publicMessage = raw.PublicMessage
+	restored.origin = raw.Origin
+	if raw.Tags.tags != nil {
+		restored.tags = raw.Tags
+	}
+	if raw.StackTrace != nil {
+		restored.stacktrace = raw.StackTrace
+	}
+	for _, subErr := range raw.SubErrors {
+		if subErr == nil {
+			continue
+		}
+		restored.subErrors = append(restored.subErrors, subErr)
+	}
+
+	*e = *restored
+	return nil
+}
+
+// UnmarshalJSON restores Tags keeping the order of keys in the JSON object
+func (tags *Tags) UnmarshalJSON(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	token, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if token == nil {
+		*tags = NewTags()
+		return nil
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '{' {
+		return fmt.Errorf("serrors: tags must be a JSON object")
+	}
+
+	restored := NewTags()
+	for dec.More() {
+		token, err = dec.Token()
+		if err != nil {
+			return err
+		}
+		key, ok := token.(string)
+		if !ok {
+			return fmt.Errorf("serrors: invalid tag key %v", token)
+		}
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return err
+		}
+		value, err := ParseTagValue(raw)
+		if err != nil {
+			return fmt.Errorf("serrors: tag %q: %w", key, err)
+		}
+		restored.SetValueSafe(key, value)
+	}
+	if _, err := dec.Token(); err != nil {
+		return err
+	}
+
+	*tags = restored
+	return nil
+}
+
+// ParseTagValue converts a JSON value written by TagValue.JsonValueString back into a TagValue
+//
+// objects become ObjectTagValue and arrays become ArrayTagValue.
+// objects written by DurationTagValue become DurationTagValue again.
+// numbers without fraction or exponent become IntTagValue if they fit into int, otherwise FloatTagValue.
+// strings become StringTagValue, including ones written by TimeTagValue, as JSON has no type of time.
+func ParseTagValue(data []byte) (TagValue, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return nil, fmt.Errorf("empty tag value")
+	}
+	switch data[0] {
+	case 'n':
+		if string(data) == "null" {
+			return NilTagValue{}, nil
+		}
+	case 't', 'f':
+		var b bool
+		if err := json.Unmarshal(data, &b); err != nil {
+			return nil, err
+		}
+		return BoolTagValue(b), nil
+	case '"':
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return nil, err
+		}
+		return StringTagValue(s), nil
+	case '{':
+		var tags Tags
+		if err := tags.UnmarshalJSON(data); err != nil {
+			return nil, err
+		}
+		if d, ok := durationFromTags(tags); ok {
+			return d, nil
+		}
+		return ObjectTagValue(tags), nil
+	case '[':
+		var items []json.RawMessage
+		if err := json.Unmarshal(data, &items); err != nil {
+			return nil, err
+		}
+		array := make(ArrayTagValue, 0, len(items))
+		for _, item := range items {
+			value, err := ParseTagValue(item)
+			if err != nil {
+				return nil, err
+			}
+			array = append(array, value)
+		}
+		return array, nil
+	case '-', '0', '1', '2', '3', '4', '5', '6', '7', '8', '9':
+		if !bytes.ContainsAny(data, ".eE") {
+			if i, err := strconv.Atoi(string(data)); err == nil {
+				return IntTagValue(i), nil
+			}
+		}
+		f, err := strconv.ParseFloat(string(data), 64)
+		if err != nil {
+			return nil, err
+		}
+		return FloatTagValue(f), nil
+	}
+	return nil, fmt.Errorf("unsupported tag value %s", data)
+}
+
+// durationFromTags restores DurationTagValue from tags written by DurationTagValue.AppendJSON()
+// tags must have exactly the same keys in the same order, and the string must agree with the nanoseconds.
+func durationFromTags(tags Tags) (DurationTagValue, bool) {
+	if len(tags.tags) != 3 || tags.tags[0].Key != "string" || tags.tags[1].Key != "nanoseconds" || tags.tags[2].Key != "milliseconds" {
+		return 0, false
+	}
+	str, ok := tags.tags[0].Value.(StringTagValue)
+	if !ok {
+		return 0, false
+	}
+	var d DurationTagValue
+	switch ns := tags.tags[1].Value.(type) {
+	case IntTagValue:
+		d = DurationTagValue(ns)
+	case FloatTagValue:
+		// nanoseconds overflowing int on 32 bit platforms
+		d = DurationTagValue(ns)
+	default:
+		return 0, false
+	}
+	if d.String() != string(str) {
+		return 0, false
+	}
+	return d, true
+}
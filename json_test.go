@@ -0,0 +1,296 @@
+package serrors
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestFromJSON(t *testing.T) {
+	tm := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	testCases := []struct {
+		label    string
+		json     string
+		expected *StructuredError
+	}{
+		{
+			label:    "no error",
+			json:     `{"type":"none","message":"<no error>","stacktrace":[]}`,
+			expected: NewRawStructuredError(nil),
+		},
+		{
+			label: "all fields",
+			json:  `{"type":"testType","message":"test error","when":"2024-01-01T12:00:00Z","request_id":"req-123","tags":{"b":"value","a":42,"c":3.14,"d":true,"e":null},"stacktrace":[{"file":"example.go","line":10,"function":"main.exampleFunction"}]}`,
+			expected: &StructuredError{
+				errorType: ErrorType("testType"),
+				err:       errors.New("test error"),
+				stacktrace: StackTrace{
+					{File: "example.go", Line: 10, Function: "main.exampleFunction"},
+				},
+				when:      &tm,
+				requestId: "req-123",
+				tags: Tags{
+					tags: []Tag{
+						{Key: "b", Value: StringTagValue("value")},
+						{Key: "a", Value: IntTagValue(42)},
+						{Key: "c", Value: FloatTagValue(3.14)},
+						{Key: "d", Value: BoolTagValue(true)},
+						{Key: "e", Value: NilTagValue{}},
+					},
+					keyMap: map[string]int{"b": 0, "a": 1, "c": 2, "d": 3, "e": 4},
+				},
+				subErrors: make([]error, 0),
+			},
+		},
+		{
+			label: "sub errors",
+			json:  `{"type":"none","message":"main error","stacktrace":[],"sub_errors":[{"type":"none","message":"sub error 1","stacktrace":[]}]}`,
+			expected: &StructuredError{
+				errorType:  ErrorTypeNone,
+				err:        errors.New("main error"),
+				stacktrace: make(StackTrace, 0),
+				tags:       NewTags(),
+				subErrors: []error{
+					NewRawStructuredError(errors.New("sub error 1")),
+				},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.label, func(t *testing.T) {
+			got, err := FromJSON([]byte(tc.json))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			assertStructuredErrorWithErrorValue(t, got, tc.expected)
+		})
+	}
+}
+
+func TestFromJSON_Invalid(t *testing.T) {
+	testCases := []struct {
+		label string
+		json  string
+	}{
+		{label: "not json", json: `not json`},
+		{label: "invalid when", json: `{"type":"none","message":"m","when":"yesterday","stacktrace":[]}`},
+		{label: "tags is not object", json: `{"type":"none","message":"m","tags":[1],"stacktrace":[]}`},
+		{label: "invalid tag value", json: `{"type":"none","message":"m","tags":{"k":nul},"stacktrace":[]}`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.label, func(t *testing.T) {
+			got, err := FromJSON([]byte(tc.json))
+			if err == nil {
+				t.Errorf("expected error, got %v", got)
+			}
+		})
+	}
+}
+
+func TestFromJSON_RoundTrip(t *testing.T) {
+	tm := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	sub := NewRawStructuredError(errors.New("sub error 2"))
+	_ = sub.SetType("subType")
+	_ = sub.AddTagFloat("ratio", 1e21)
+	_ = sub.WithStackTrace()
+
+	fe := NewRawStructuredError(errors.New("main \"error\"\nline2"))
+	_ = fe.SetType("testType")
+	_ = fe.SetWhen(tm)
+	_ = fe.SetRequestID("req-123")
+	_ = fe.SetPublicMessage("something went wrong")
+	_ = fe.AddTagString("str", "value")
+	_ = fe.AddTagInt("int", -7)
+	_ = fe.AddTagFloat("float", 2.5)
+	_ = fe.AddTagFloat("integral_float", 3)
+	_ = fe.AddTagDuration("duration", 90*time.Minute)
+	_ = fe.AddTagBool("bool", false)
+	_ = fe.AddTagSafe("nil", NilTagValue{})
+	_ = fe.AddTag("object", map[string]any{"id": 1, "plan": "pro"})
+	_ = fe.AddTag("array", []int{1, 2, 3})
+	_ = fe.WithStackTrace()
+	_ = fe.AddSubError(errors.New("sub error 1"), sub)
+
+	expected := ToJsonString(fe)
+	restored, err := FromJSON([]byte(expected))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got := ToJsonString(restored)
+	if got != expected {
+		t.Errorf("expected %s, got %s", expected, got)
+	}
+	if v, _ := restored.tags.GetValue("integral_float"); v != FloatTagValue(3) {
+		t.Errorf("expected FloatTagValue(3), got %#v", v)
+	}
+	if v, _ := restored.tags.GetValue("duration"); v != DurationTagValue(90*time.Minute) {
+		t.Errorf("expected DurationTagValue(90m), got %#v", v)
+	}
+	for _, key := range []string{"object", "array"} {
+		original, _ := fe.tags.GetValue(key)
+		v, _ := restored.tags.GetValue(key)
+		if reflect.TypeOf(v) != reflect.TypeOf(original) {
+			t.Errorf("expected %s to be restored as %T, got %T", key, original, v)
+		}
+	}
+}
+
+func TestStructuredError_MarshalJSON(t *testing.T) {
+	fe := NewRawStructuredError(errors.New("test error"))
+	_ = fe.SetType("testType")
+	_ = fe.AddTagInt("key", 1)
+
+	testCases := []struct {
+		label    string
+		value    any
+		expected string
+	}{
+		{
+			label:    "pointer",
+			value:    fe,
+			expected: `{"type":"testType","message":"test error","tags":{"key":1},"stacktrace":[]}`,
+		},
+		{
+			label:    "value",
+			value:    *fe,
+			expected: `{"type":"testType","message":"test error","tags":{"key":1},"stacktrace":[]}`,
+		},
+		{
+			label:    "nil pointer",
+			value:    (*StructuredError)(nil),
+			expected: `null`,
+		},
+		{
+			label: "struct field",
+			value: struct {
+				Code int   `json:"code"`
+				Err  error `json:"error"`
+			}{Code: 500, Err: fe},
+			expected: `{"code":500,"error":{"type":"testType","message":"test error","tags":{"key":1},"stacktrace":[]}}`,
+		},
+		{
+			label:    "slice of errors",
+			value:    []error{fe, nil},
+			expected: `[{"type":"testType","message":"test error","tags":{"key":1},"stacktrace":[]},null]`,
+		},
+		{
+			label:    "map of errors",
+			value:    map[string]error{"main": fe},
+			expected: `{"main":{"type":"testType","message":"test error","tags":{"key":1},"stacktrace":[]}}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.label, func(t *testing.T) {
+			got, err := json.Marshal(tc.value)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(got) != tc.expected {
+				t.Errorf("expected %s, got %s", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestMarshalJSON_Parts(t *testing.T) {
+	tags := NewTags()
+	tags.SetValueSafe("s", StringTagValue("a\"b"))
+	tags.SetValueSafe("i", IntTagValue(1))
+	tags.SetValueSafe("f", FloatTagValue(1.5))
+	tags.SetValueSafe("b", BoolTagValue(true))
+	tags.SetValueSafe("n", NilTagValue{})
+
+	testCases := []struct {
+		label    string
+		value    any
+		expected string
+	}{
+		{
+			label:    "tags",
+			value:    tags,
+			expected: `{"s":"a\"b","i":1,"f":1.5,"b":true,"n":null}`,
+		},
+		{
+			label: "stacktrace",
+			value: StackTrace{
+				{File: "example.go", Line: 10, Function: "main.exampleFunction"},
+			},
+			expected: `[{"file":"example.go","line":10,"function":"main.exampleFunction"}]`,
+		},
+		{
+			label:    "nil stacktrace",
+			value:    StackTrace(nil),
+			expected: `[]`,
+		},
+		{
+			label:    "tag values",
+			value:    []TagValue{StringTagValue("x"), IntTagValue(2), FloatTagValue(0.5), BoolTagValue(false), NilTagValue{}},
+			expected: `["x",2,0.5,false,null]`,
+		},
+		{
+			label: "printer",
+			value: ErrorJsonPrinter{
+				errorType: ErrorType("testType"),
+				err:       errors.New("printer error"),
+			},
+			expected: `{"type":"testType","message":"printer error","stacktrace":[]}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.label, func(t *testing.T) {
+			got, err := json.Marshal(tc.value)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(got) != tc.expected {
+				t.Errorf("expected %s, got %s", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestParseTagValue(t *testing.T) {
+	testCases := []struct {
+		label    string
+		json     string
+		expected TagValue
+	}{
+		{label: "string", json: `"text"`, expected: StringTagValue("text")},
+		{label: "int", json: `-12`, expected: IntTagValue(-12)},
+		{label: "float", json: `1.5e3`, expected: FloatTagValue(1500)},
+		{label: "integral float", json: `3.0`, expected: FloatTagValue(3)},
+		{label: "duration", json: `{"string":"1.5s","nanoseconds":1500000000,"milliseconds":1500}`, expected: DurationTagValue(1500 * time.Millisecond)},
+		{label: "object like duration", json: `{"string":"2s","nanoseconds":1500000000,"milliseconds":1500}`, expected: newTestObjectTagValue(
+			"string", StringTagValue("2s"),
+			"nanoseconds", IntTagValue(1500000000),
+			"milliseconds", IntTagValue(1500),
+		)},
+		{label: "time", json: `"2024-01-01T12:00:00Z"`, expected: StringTagValue("2024-01-01T12:00:00Z")},
+		{label: "int overflow", json: `123456789012345678901234567890`, expected: FloatTagValue(123456789012345678901234567890)},
+		{label: "bool", json: `false`, expected: BoolTagValue(false)},
+		{label: "null", json: ` null `, expected: NilTagValue{}},
+		{label: "array", json: `[1, "a", [true]]`, expected: ArrayTagValue{IntTagValue(1), StringTagValue("a"), ArrayTagValue{BoolTagValue(true)}}},
+		{label: "object", json: `{"z": 1, "a": {"b": null}}`, expected: newTestObjectTagValue(
+			"z", IntTagValue(1),
+			"a", newTestObjectTagValue("b", NilTagValue{}),
+		)},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.label, func(t *testing.T) {
+			got, err := ParseTagValue([]byte(tc.json))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tc.expected) {
+				t.Errorf("expected %#v, got %#v", tc.expected, got)
+			}
+		})
+	}
+}
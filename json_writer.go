@@ -0,0 +1,130 @@
+package serrors
+
+import (
+	"io"
+	"sync"
+	"unicode/utf8"
+)
+
+// JsonAppender appends its JSON representation to dst and returns the extended buffer
+// TagValue and JsonPrinter implementing it are written without building intermediate strings.
+type JsonAppender interface {
+	AppendJSON(dst []byte) []byte
+}
+
+// buffers larger than this are not returned to the pool to avoid holding huge memory
+const maxPooledJsonBufferSize = 64 * 1024
+
+var jsonBufferPool = sync.Pool{
+	New: func() any {
+		b := make([]byte, 0, 1024)
+		return &b
+	},
+}
+
+// writeJSON appends JSON by appendFunc to a pooled buffer and writes it to w
+func writeJSON(w io.Writer, appendFunc func(dst []byte) []byte) error {
+	bp := jsonBufferPool.Get().(*[]byte)
+	b := appendFunc((*bp)[:0])
+	_, err := w.Write(b)
+	putJsonBuffer(bp, b)
+	return err
+}
+
+// printJSON builds JSON string by appendFunc using a pooled buffer
+func printJSON(appendFunc func(dst []byte) []byte) string {
+	bp := jsonBufferPool.Get().(*[]byte)
+	b := appendFunc((*bp)[:0])
+	s := string(b)
+	putJsonBuffer(bp, b)
+	return s
+}
+
+func putJsonBuffer(bp *[]byte, b []byte) {
+	if cap(b) > maxPooledJsonBufferSize {
+		return
+	}
+	*bp = b
+	jsonBufferPool.Put(bp)
+}
+
+// appendJsonValue appends JSON of v, using AppendJSON if v implements JsonAppender
+func appendJsonValue(dst []byte, v TagValue) []byte {
+	return appendJsonValueDepth(dst, v, 1)
+}
+
+// nestedTagValue is implemented by tag values containing other tag values
+// depth is the nesting level of the value itself, starting at 1 for values of Tags
+type nestedTagValue interface {
+	appendJSONDepth(dst []byte, depth int) []byte
+	verboseLines(dst []string, indent string, depth int) []string
+}
+
+func appendJsonValueDepth(dst []byte, v TagValue, depth int) []byte {
+	if n, ok := v.(nestedTagValue); ok {
+		return n.appendJSONDepth(dst, depth)
+	}
+	if v == nil {
+		return append(dst, "null"...)
+	}
+	if a, ok := v.(JsonAppender); ok {
+		return a.AppendJSON(dst)
+	}
+	return append(dst, v.JsonValueString()...)
+}
+
+const hexDigits = "0123456789abcdef"
+
+// appendJsonString appends s as a quoted JSON string
+// escaping is the same as json.Marshal including HTML characters and invalid UTF-8
+func appendJsonString(dst []byte, s string) []byte {
+	dst = append(dst, '"')
+	start := 0
+	for i := 0; i < len(s); {
+		if b := s[i]; b < utf8.RuneSelf {
+			if b >= 0x20 && b != '"' && b != '\\' && b != '<' && b != '>' && b != '&' {
+				i++
+				continue
+			}
+			dst = append(dst, s[start:i]...)
+			switch b {
+			case '\\', '"':
+				dst = append(dst, '\\', b)
+			case '\b':
+				dst = append(dst, '\\', 'b')
+			case '\f':
+				dst = append(dst, '\\', 'f')
+			case '\n':
+				dst = append(dst, '\\', 'n')
+			case '\r':
+				dst = append(dst, '\\', 'r')
+			case '\t':
+				dst = append(dst, '\\', 't')
+			default:
+				dst = append(dst, '\\', 'u', '0', '0', hexDigits[b>>4], hexDigits[b&0xF])
+			}
+			i++
+			start = i
+			continue
+		}
+		c, size := utf8.DecodeRuneInString(s[i:])
+		if c == utf8.RuneError && size == 1 {
+			dst = append(dst, s[start:i]...)
+			dst = append(dst, "\ufffd"...)
+			i += size
+			start = i
+			continue
+		}
+		// U+2028 and U+2029 are valid JSON but break JavaScript
+		if c == '\u2028' || c == '\u2029' {
+			dst = append(dst, s[start:i]...)
+			dst = append(dst, '\\', 'u', '2', '0', '2', hexDigits[c&0xF])
+			i += size
+			start = i
+			continue
+		}
+		i += size
+	}
+	dst = append(dst, s[start:]...)
+	return append(dst, '"')
+}
@@ -0,0 +1,103 @@
+package serrors
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestAppendJsonString(t *testing.T) {
+	testCases := []struct {
+		label string
+		value string
+	}{
+		{label: "empty", value: ""},
+		{label: "ascii", value: "simple text"},
+		{label: "quotes and backslash", value: `say "hello" \ bye`},
+		{label: "control characters", value: "line1\nline2\r\ttab\b\f\x00\x1f"},
+		{label: "html characters", value: "<a href=\"x\">&</a>"},
+		{label: "multibyte", value: "エラー 🚀"},
+		{label: "line separators", value: "a b c"},
+		{label: "invalid utf8", value: "a\xffb\xc3"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.label, func(t *testing.T) {
+			expected, _ := json.Marshal(tc.value)
+			got := appendJsonString([]byte("prefix:"), tc.value)
+			if string(got) != "prefix:"+string(expected) {
+				t.Errorf("expected %s, got %s", "prefix:"+string(expected), got)
+			}
+		})
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("write failed")
+}
+
+func TestErrorJsonPrinter_WriteJSON(t *testing.T) {
+	tm := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	printer := ErrorJsonPrinter{
+		errorType:  ErrorType("testType"),
+		err:        errors.New("test error"),
+		stacktrace: StackTrace{{File: "example.go", Line: 10, Function: "main.exampleFunction"}},
+		when:       &tm,
+		requestId:  "req-123",
+		tags: Tags{
+			tags:   []Tag{{Key: "key1", Value: StringTagValue("value1")}},
+			keyMap: map[string]int{"key1": 0},
+		},
+		subErrors: []error{errors.New("sub error")},
+	}
+	expected := `{"type":"testType","message":"test error","when":"2024-01-01T12:00:00Z","request_id":"req-123","tags":{"key1":"value1"},"stacktrace":[{"file":"example.go","line":10,"function":"main.exampleFunction"}],"sub_errors":[{"type":"none","message":"sub error","stacktrace":[]}]}`
+
+	buf := &bytes.Buffer{}
+	if err := printer.WriteJSON(buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if buf.String() != expected {
+		t.Errorf("expected %s, got %s", expected, buf.String())
+	}
+	if got := printer.Print(); got != expected {
+		t.Errorf("expected Print() %s, got %s", expected, got)
+	}
+	if got := string(printer.AppendJSON([]byte("log="))); got != "log="+expected {
+		t.Errorf("expected AppendJSON() %s, got %s", "log="+expected, got)
+	}
+	if err := printer.WriteJSON(failingWriter{}); err == nil {
+		t.Errorf("expected write error, got nil")
+	}
+}
+
+func TestStructuredError_WriteJSON(t *testing.T) {
+	fe := NewRawStructuredError(errors.New("test error"))
+	_ = fe.AddTagInt("key", 1)
+	expected := `{"type":"none","message":"test error","tags":{"key":1},"stacktrace":[]}`
+
+	buf := &bytes.Buffer{}
+	if err := fe.WriteJSON(buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if buf.String() != expected {
+		t.Errorf("expected %s, got %s", expected, buf.String())
+	}
+	if got := string(fe.AppendJSON(nil)); got != expected {
+		t.Errorf("expected %s, got %s", expected, got)
+	}
+}
+
+func TestStructuredError_AppendJSON_Allocs(t *testing.T) {
+	fe := newBenchmarkJsonError()
+	buf := make([]byte, 0, 64*1024)
+	allocs := testing.AllocsPerRun(100, func() {
+		buf = fe.AppendJSON(buf[:0])
+	})
+	if allocs != 0 {
+		t.Errorf("expected AppendJSON with sub errors not to allocate, got %v allocs", allocs)
+	}
+}
@@ -0,0 +1,120 @@
+package serrors
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+)
+
+// TypePanic is the type of errors recovered from panics by Recover() and RecoverValue()
+const TypePanic ErrorType = "panic"
+
+// PanicValueTagKey is the tag key of panic values which are not error
+const PanicValueTagKey string = "panic_value"
+
+// panicFrameMargin is the number of extra frames captured for frames between the panic and the recovery
+const panicFrameMargin int = 16
+
+// RecoverValue converts a value returned by recover() into StructuredError of TypePanic
+// It must be called in the deferred function which called recover(), as the stack trace starts at the panicking function.
+// if r is error, it is wrapped and can be reached by errors.Is() and errors.As().
+// otherwise the message is "panic: <r>" and r is kept as tag of PanicValueTagKey.
+// if r is nil, it returns nil.
+//
+//	defer func() {
+//		if r := recover(); r != nil {
+//			log(serrors.RecoverValue(r))
+//		}
+//	}()
+func RecoverValue(r any) SError {
+	if r == nil {
+		return nil
+	}
+	var fe *StructuredError
+	if err, ok := r.(error); ok {
+		fe = NewRawStructuredError(err)
+	} else {
+		fe = NewRawStructuredError(fmt.Errorf("panic: %v", r))
+		_ = fe.AddTag(PanicValueTagKey, r)
+	}
+	fe.errorType = TypePanic
+	fe.stacktrace = panicStackTrace(1, MaxStackTraceDepth) // skip 1 to skip RecoverValue
+	return fe
+}
+
+// RecoverValueForClients is similar to RecoverValue() but drops the tag of PanicValueTagKey
+// use it for errors written to clients, e.g. by HTTP middleware and gRPC interceptors, as the panic value can contain internal details.
+func RecoverValueForClients(r any) SError {
+	fe := RecoverValue(r)
+	if fe != nil {
+		_ = fe.DeleteTag(PanicValueTagKey)
+	}
+	return fe
+}
+
+// RecoverOption configures Recover()
+type RecoverOption func(o *recoverOptions)
+
+type recoverOptions struct {
+	repanicRuntimeErrors bool
+}
+
+// RepanicRuntimeErrors makes Recover() panic again with runtime.Error such as nil pointer dereference,
+// which usually means a bug the program cannot continue safely with.
+func RepanicRuntimeErrors() RecoverOption {
+	return func(o *recoverOptions) {
+		o.repanicRuntimeErrors = true
+	}
+}
+
+// Recover recovers a panic into *errp as StructuredError of TypePanic. See RecoverValue().
+// It must be deferred directly, otherwise recover() does not stop the panic.
+// if *errp is already set, it is kept as a sub error of the panic error.
+// if errp is nil, the panic continues.
+//
+//	func run() (err error) {
+//		defer serrors.Recover(&err)
+//		...
+//	}
+func Recover(errp *error, options ...RecoverOption) {
+	r := recover()
+	if r == nil {
+		return
+	}
+	o := recoverOptions{}
+	for _, option := range options {
+		option(&o)
+	}
+	if errp == nil {
+		panic(r)
+	}
+	if _, ok := r.(runtime.Error); ok && o.repanicRuntimeErrors {
+		panic(r)
+	}
+	fe := RecoverValue(r)
+	if *errp != nil {
+		_ = fe.AddSubError(*errp)
+	}
+	*errp = fe
+}
+
+// panicStackTrace captures stack trace starting at the panicking function
+// frames of the recovery and runtime frames of the panic are skipped.
+// if the caller is not recovering a panic, it starts from the caller of panicStackTrace() after skipping skip frames.
+func panicStackTrace(skip int, maxDepth int) StackTrace {
+	st := newCallers(skip+1, maxDepth+panicFrameMargin).StackTrace() // skip +1 to skip panicStackTrace
+	for i := len(st) - 1; i >= 0; i-- {
+		if st[i].Function != "runtime.gopanic" {
+			continue
+		}
+		st = st[i+1:]
+		for len(st) > 0 && strings.HasPrefix(st[0].Function, "runtime.") {
+			st = st[1:]
+		}
+		break
+	}
+	if len(st) > maxDepth {
+		st = st[:maxDepth]
+	}
+	return st
+}
@@ -0,0 +1,154 @@
+package serrors
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func panicking(v any) {
+	panic(v)
+}
+
+func runPanicking(v any, options ...RecoverOption) (err error) {
+	defer Recover(&err, options...)
+	panicking(v)
+	return nil
+}
+
+func TestRecover(t *testing.T) {
+	sentinel := errors.New("sentinel")
+	testCases := []struct {
+		label           string
+		v               any
+		expectedMessage string
+		expectedTag     string
+	}{
+		{label: "string", v: "something broke", expectedMessage: "[Type: panic] panic: something broke", expectedTag: "something broke"},
+		{label: "int", v: 42, expectedMessage: "[Type: panic] panic: 42", expectedTag: "42"},
+		{label: "error", v: sentinel, expectedMessage: "[Type: panic] sentinel"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.label, func(t *testing.T) {
+			err := runPanicking(tc.v)
+			if err == nil {
+				t.Fatalf("expected error")
+			}
+			if err.Error() != tc.expectedMessage {
+				t.Errorf("expected message %s, got %s", tc.expectedMessage, err.Error())
+			}
+			if !IsType(err, TypePanic) {
+				t.Errorf("expected TypePanic")
+			}
+			fe := err.(*StructuredError)
+			v, ok := fe.Tags().GetValue(PanicValueTagKey)
+			if tc.expectedTag == "" {
+				if ok {
+					t.Errorf("expected no panic value tag, got %v", v)
+				}
+				if !errors.Is(err, sentinel) {
+					t.Errorf("expected panic error to be wrapped")
+				}
+			} else if !ok || v.String() != tc.expectedTag {
+				t.Errorf("expected panic value tag %s, got %v", tc.expectedTag, v)
+			}
+			st := fe.StackTrace()
+			if len(st) < 2 || st[0].Function != "github.com/hinoguma/go-structured-error.panicking" ||
+				st[1].Function != "github.com/hinoguma/go-structured-error.runPanicking" {
+				t.Errorf("expected stack trace to start at the panicking function, got %v", st)
+			}
+		})
+	}
+}
+
+func TestRecover_RuntimeError(t *testing.T) {
+	var m map[string]int
+	err := func() (err error) {
+		defer Recover(&err)
+		m["a"] = 1
+		return nil
+	}()
+	if !IsType(err, TypePanic) {
+		t.Fatalf("expected TypePanic, got %v", err)
+	}
+	st := err.(*StructuredError).StackTrace()
+	if len(st) == 0 || !strings.HasPrefix(st[0].Function, "github.com/hinoguma/go-structured-error.TestRecover_RuntimeError") {
+		t.Errorf("expected stack trace to start at the panicking function, got %v", st)
+	}
+}
+
+func TestRecover_RepanicRuntimeErrors(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("expected runtime error to be re-panicked")
+		}
+	}()
+	_ = runPanicking(nil, RepanicRuntimeErrors()) // panic(nil) is *runtime.PanicNilError
+}
+
+func TestRecover_RepanicRuntimeErrorsKeepsOthers(t *testing.T) {
+	err := runPanicking("not a runtime error", RepanicRuntimeErrors())
+	if !IsType(err, TypePanic) {
+		t.Errorf("expected TypePanic, got %v", err)
+	}
+}
+
+func TestRecover_KeepsReturnedError(t *testing.T) {
+	returned := errors.New("returned")
+	err := func() (err error) {
+		defer Recover(&err)
+		defer func() { err = returned }()
+		panicking("boom")
+		return nil
+	}()
+	if !errors.Is(err, returned) || !IsType(err, TypePanic) {
+		t.Errorf("expected panic error with returned error as sub error, got %v", err)
+	}
+}
+
+func TestRecover_NoPanic(t *testing.T) {
+	err := func() (err error) {
+		defer Recover(&err)
+		return nil
+	}()
+	if err != nil {
+		t.Errorf("expected nil, got %v", err)
+	}
+}
+
+func TestRecoverValue(t *testing.T) {
+	if RecoverValue(nil) != nil {
+		t.Errorf("expected nil")
+	}
+	var fe SError
+	func() {
+		defer func() {
+			fe = RecoverValue(recover())
+		}()
+		panicking("boom")
+	}()
+	st := fe.StackTrace()
+	if len(st) == 0 || st[0].Function != "github.com/hinoguma/go-structured-error.panicking" {
+		t.Errorf("expected stack trace to start at the panicking function, got %v", st)
+	}
+}
+
+func TestRecoverValueForClients(t *testing.T) {
+	if RecoverValueForClients(nil) != nil {
+		t.Errorf("expected nil")
+	}
+	var fe SError
+	func() {
+		defer func() {
+			fe = RecoverValueForClients(recover())
+		}()
+		panicking("password=secret")
+	}()
+	if _, ok := fe.(*StructuredError).Tags().GetValue(PanicValueTagKey); ok {
+		t.Errorf("expected panic value tag to be dropped")
+	}
+	st := fe.StackTrace()
+	if !IsType(fe, TypePanic) || len(st) == 0 || st[0].Function != "github.com/hinoguma/go-structured-error.panicking" {
+		t.Errorf("expected panic error with stack trace from the panicking function, got %v %v", fe, st)
+	}
+}
@@ -1,8 +1,7 @@
 package serrors
 
 import (
-	"encoding/json"
-	"fmt"
+	"io"
 	"strconv"
 	"strings"
 	"time"
@@ -11,6 +10,39 @@ import (
 const indentation string = "    "
 const JsonItemSeparator string = ","
 
+// printOptions are options of printers shared with printers of sub errors
+type printOptions struct {
+	// redactor is used instead of DefaultRedactor() if set
+	redactor Redactor
+	// scrubber is used instead of DefaultScrubber() if set
+	scrubber Scrubber
+}
+
+func (o printOptions) activeRedactor() Redactor {
+	if o.redactor != nil {
+		return o.redactor
+	}
+	return DefaultRedactor()
+}
+
+func (o printOptions) activeScrubber() Scrubber {
+	if o.scrubber != nil {
+		return o.scrubber
+	}
+	return DefaultScrubber()
+}
+
+// inherit returns o overridden by options set in parent
+func (o printOptions) inherit(parent printOptions) printOptions {
+	if parent.redactor != nil {
+		o.redactor = parent.redactor
+	}
+	if parent.scrubber != nil {
+		o.scrubber = parent.scrubber
+	}
+	return o
+}
+
 type JsonPrinter interface {
 	Print() string
 }
@@ -22,95 +54,193 @@ type ErrorJsonPrinter struct {
 	stacktrace StackTrace
 
 	// optional
-	when      *time.Time
-	requestId string
-	tags      Tags
-	subErrors []error
+	when          *time.Time
+	requestId     string
+	tags          Tags
+	subErrors     []error
+	publicMessage string
+	origin        string
+
+	// options are inherited by printers of sub errors
+	options printOptions
+}
+
+// WithRedactor returns a copy of the printer redacting tags by r
+// sub errors are redacted by r too.
+func (f ErrorJsonPrinter) WithRedactor(r Redactor) ErrorJsonPrinter {
+	f.options.redactor = r
+	return f
+}
+
+// WithScrubber returns a copy of the printer scrubbing messages by s
+// messages of sub errors are scrubbed by s too.
+func (f ErrorJsonPrinter) WithScrubber(s Scrubber) ErrorJsonPrinter {
+	f.options.scrubber = s
+	return f
 }
 
 func (f ErrorJsonPrinter) Print() string {
-	jsonStr := "{"
-	jsonStr += BuildJsonStringOfType(f.errorType)
-	jsonStr += JsonItemSeparator + BuildJsonStringOfMessage(f.err)
+	return printJSON(f.AppendJSON)
+}
+
+// WriteJSON writes the same JSON as Print() to w using a pooled buffer
+func (f ErrorJsonPrinter) WriteJSON(w io.Writer) error {
+	return writeJSON(w, f.AppendJSON)
+}
+
+// AppendJSON appends the same JSON as Print() to dst
+func (f ErrorJsonPrinter) AppendJSON(dst []byte) []byte {
+	dst = append(dst, '{')
+	dst = appendJsonOfType(dst, f.errorType)
+	dst = append(dst, JsonItemSeparator...)
+	dst = appendJsonOfMessage(dst, f.err, f.options.activeScrubber())
 
 	if f.when != nil {
-		jsonStr += JsonItemSeparator + BuildJsonStringOfWhen(*f.when, time.RFC3339)
+		dst = append(dst, JsonItemSeparator...)
+		dst = appendJsonOfWhen(dst, *f.when, time.RFC3339)
 	}
 	if f.requestId != "" {
-		jsonStr += JsonItemSeparator + BuildJsonStringOfRequestID(f.requestId)
+		dst = append(dst, JsonItemSeparator...)
+		dst = appendJsonOfRequestID(dst, f.requestId)
+	}
+	if f.publicMessage != "" {
+		dst = append(dst, JsonItemSeparator...)
+		dst = appendJsonOfPublicMessage(dst, f.publicMessage)
+	}
+	if f.origin != "" {
+		dst = append(dst, JsonItemSeparator...)
+		dst = append(dst, `"origin":`...)
+		dst = appendJsonString(dst, f.origin)
 	}
 
 	if len(f.tags.tags) > 0 {
-		jsonStr += JsonItemSeparator + BuildJsonStringOfTags(f.tags)
+		dst = append(dst, JsonItemSeparator...)
+		dst = appendJsonOfTags(dst, redactTags(f.tags, f.options.activeRedactor()))
 	}
 
-	jsonStr += JsonItemSeparator + BuildJsonStringOfStackTrace(f.stacktrace)
+	dst = append(dst, JsonItemSeparator...)
+	dst = appendJsonOfStackTrace(dst, f.stacktrace)
 
 	if len(f.subErrors) > 0 {
-		jsonStr += JsonItemSeparator + BuildJsonStringOfSubErrors(f.subErrors)
+		dst = append(dst, JsonItemSeparator...)
+		dst = appendJsonOfSubErrors(dst, f.subErrors, f.options)
 	}
-	jsonStr += "}"
-	return jsonStr
+	return append(dst, '}')
 }
 
 func BuildJsonStringOfType(t ErrorType) string {
-	return `"type":"` + t.StringWithDefaultNone() + `"`
+	return string(appendJsonOfType(nil, t))
+}
+
+func appendJsonOfType(dst []byte, t ErrorType) []byte {
+	dst = append(dst, `"type":`...)
+	return appendJsonString(dst, t.StringWithDefaultNone())
 }
 
 func BuildJsonStringOfMessage(err error) string {
+	return string(appendJsonOfMessage(nil, err, DefaultScrubber()))
+}
+
+// appendJsonOfMessage appends the message of err scrubbed by scrubber if not nil
+func appendJsonOfMessage(dst []byte, err error, scrubber Scrubber) []byte {
 	if err == nil {
-		return `"message":"` + NoErrStr + `"`
+		return append(dst, `"message":"`+NoErrStr+`"`...)
 	}
-	escaped, _ := json.Marshal(err.Error())
-	return `"message":` + string(escaped)
+	dst = append(dst, `"message":`...)
+	return appendJsonString(dst, scrubMessage(err.Error(), scrubber))
 }
 
 func BuildJsonStringOfWhen(t time.Time, layout string) string {
-	return fmt.Sprintf(`"when":"%s"`, t.Format(layout))
+	return string(appendJsonOfWhen(nil, t, layout))
+}
+
+func appendJsonOfWhen(dst []byte, t time.Time, layout string) []byte {
+	dst = append(dst, `"when":"`...)
+	dst = t.AppendFormat(dst, layout)
+	return append(dst, '"')
 }
 
 func BuildJsonStringOfRequestID(requestId string) string {
-	escaped, _ := json.Marshal(requestId)
-	return `"request_id":` + string(escaped)
+	return string(appendJsonOfRequestID(nil, requestId))
+}
+
+func appendJsonOfRequestID(dst []byte, requestId string) []byte {
+	dst = append(dst, `"request_id":`...)
+	return appendJsonString(dst, requestId)
+}
+
+func BuildJsonStringOfPublicMessage(message string) string {
+	return string(appendJsonOfPublicMessage(nil, message))
+}
+
+func appendJsonOfPublicMessage(dst []byte, message string) []byte {
+	dst = append(dst, `"public_message":`...)
+	return appendJsonString(dst, message)
 }
 
 func BuildJsonStringOfTags(tags Tags) string {
-	return `"tags":` + tags.JsonValueString()
+	return string(appendJsonOfTags(nil, tags))
+}
+
+func appendJsonOfTags(dst []byte, tags Tags) []byte {
+	dst = append(dst, `"tags":`...)
+	return tags.AppendJSON(dst)
 }
 
 func BuildJsonStringOfStackTrace(stacktrace StackTrace) string {
-	if len(stacktrace) == 0 {
-		return `"stacktrace":[]`
-	}
-	return `"stacktrace":` + stacktrace.JsonValueString()
+	return string(appendJsonOfStackTrace(nil, stacktrace))
+}
+
+func appendJsonOfStackTrace(dst []byte, stacktrace StackTrace) []byte {
+	dst = append(dst, `"stacktrace":`...)
+	return stacktrace.AppendJSON(dst)
 }
 
 func BuildJsonStringOfSubErrors(subErrors []error) string {
-	jsonStr := `"sub_errors":[`
+	return string(appendJsonOfSubErrors(nil, subErrors, printOptions{}))
+}
+
+// appendJsonOfSubErrors appends sub errors
+// options are inherited by ErrorJsonPrinter of sub errors.
+func appendJsonOfSubErrors(dst []byte, subErrors []error, options printOptions) []byte {
+	dst = append(dst, `"sub_errors":[`...)
 	isFirst := true
 	for _, subErr := range subErrors {
 		if subErr == nil {
 			continue
 		}
-		var jf JsonPrinter
-		fe, ok := subErr.(HasJsonPrinter)
-		if ok {
-			jf = fe.JsonPrinter()
+		if isFirst {
+			isFirst = false
 		} else {
-			jf = ErrorJsonPrinter{
+			dst = append(dst, ',')
+		}
+		var ef ErrorJsonPrinter
+		switch fe := subErr.(type) {
+		case *StructuredError:
+			// avoid boxing ErrorJsonPrinter into JsonPrinter, which allocates for every sub error
+			ef = fe.errorJsonPrinter()
+		case HasJsonPrinter:
+			jf := fe.JsonPrinter()
+			p, ok := jf.(ErrorJsonPrinter)
+			if !ok {
+				if a, ok := jf.(JsonAppender); ok {
+					dst = a.AppendJSON(dst)
+				} else {
+					dst = append(dst, jf.Print()...)
+				}
+				continue
+			}
+			ef = p
+		default:
+			ef = ErrorJsonPrinter{
 				errorType: ErrorTypeNone,
 				err:       subErr,
 			}
 		}
-		if isFirst {
-			isFirst = false
-		} else {
-			jsonStr += `,`
-		}
-		jsonStr += jf.Print()
+		ef.options = ef.options.inherit(options)
+		dst = ef.AppendJSON(dst)
 	}
-	jsonStr += `]`
-	return jsonStr
+	return append(dst, ']')
 }
 
 type VerbosePrinter interface {
@@ -125,10 +255,29 @@ type ErrorVerbosePrinter struct {
 	stacktrace StackTrace
 
 	// optional
-	when      *time.Time
-	requestId string
-	tags      Tags
-	subErrors []error
+	when          *time.Time
+	requestId     string
+	tags          Tags
+	subErrors     []error
+	publicMessage string
+	origin        string
+
+	// options are inherited by printers of sub errors
+	options printOptions
+}
+
+// WithRedactor returns a copy of the printer redacting tags by r
+// sub errors are redacted by r too.
+func (f ErrorVerbosePrinter) WithRedactor(r Redactor) ErrorVerbosePrinter {
+	f.options.redactor = r
+	return f
+}
+
+// WithScrubber returns a copy of the printer scrubbing messages by s
+// messages of sub errors are scrubbed by s too.
+func (f ErrorVerbosePrinter) WithScrubber(s Scrubber) ErrorVerbosePrinter {
+	f.options.scrubber = s
+	return f
 }
 
 func (f ErrorVerbosePrinter) Print() string {
@@ -152,6 +301,7 @@ func (f ErrorVerbosePrinter) Print() string {
 				}
 			}
 			subFormatter.title = f.title + ".sub" + strconv.Itoa(i+1)
+			subFormatter.options = subFormatter.options.inherit(f.options)
 			txt += "\n" + subFormatter.Print()
 		}
 	}
@@ -163,7 +313,7 @@ func (f ErrorVerbosePrinter) printSingle() string {
 	if f.err == nil {
 		txt += "\n" + "message: " + NoErrStr
 	} else {
-		txt += "\n" + "message: " + f.err.Error()
+		txt += "\n" + "message: " + scrubMessage(f.err.Error(), f.options.activeScrubber())
 	}
 	txt += "\n" + "type: " + f.errorType.StringWithDefaultNone()
 
@@ -173,12 +323,20 @@ func (f ErrorVerbosePrinter) printSingle() string {
 	if f.requestId != "" {
 		txt += "\n" + "request_id: " + f.requestId
 	}
+	if f.publicMessage != "" {
+		txt += "\n" + "public_message: " + f.publicMessage
+	}
+	if f.origin != "" {
+		txt += "\n" + "origin: " + f.origin
+	}
 
 	// tags
 	if len(f.tags.tags) > 0 {
 		txt += "\n" + "tags:"
-		for _, tag := range f.tags.tags {
-			txt += "\n" + indentation + tag.Key + ": " + tag.Value.String()
+		for _, tag := range redactTags(f.tags, f.options.activeRedactor()).tags {
+			for _, line := range appendVerboseTagLines(nil, indentation, tag.Key+":", tag.Value, 1) {
+				txt += "\n" + line
+			}
 		}
 	}
 
@@ -193,3 +351,29 @@ func (f ErrorVerbosePrinter) printSingle() string {
 	txt = f.title + ":" + txt
 	return txt
 }
+
+// appendVerboseTagLines appends lines of a tag value for verbose output
+// ObjectTagValue and ArrayTagValue are printed as indented blocks like below.
+//
+//	user:
+//	    id: 1
+//	    roles:
+//	        - admin
+func appendVerboseTagLines(dst []string, indent string, label string, v TagValue, depth int) []string {
+	if v == nil {
+		return append(dst, indent+label+" null")
+	}
+	n, ok := v.(nestedTagValue)
+	if !ok {
+		return append(dst, indent+label+" "+v.String())
+	}
+	if depth > MaxTagValueDepth {
+		return append(dst, indent+label+" "+TruncatedTagValueStr)
+	}
+	children := n.verboseLines(nil, indent+indentation, depth)
+	if len(children) == 0 {
+		return append(dst, indent+label+" "+v.String())
+	}
+	dst = append(dst, indent+label)
+	return append(dst, children...)
+}
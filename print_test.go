@@ -1,7 +1,9 @@
 package serrors
 
 import (
+	"encoding/json"
 	"errors"
+	"math"
 	"testing"
 	"time"
 )
@@ -220,6 +222,47 @@ func TestErrorVerbosePrinter_printSingle(t *testing.T) {
     message: error with empty tags
     type: none`,
 		},
+		{
+			label: "nested tags",
+			formatter: ErrorVerbosePrinter{
+				title: "main_error",
+				err:   errors.New("error with nested tags"),
+				tags: Tags{
+					tags: []Tag{
+						{Key: "user", Value: newTestObjectTagValue(
+							"id", IntTagValue(1),
+							"roles", ArrayTagValue{StringTagValue("admin"), ArrayTagValue{IntTagValue(2)}},
+							"empty", ArrayTagValue{},
+						)},
+						{Key: "deep", Value: newTestNestedArray(MaxTagValueDepth + 1)},
+					},
+					keyMap: map[string]int{
+						"user": 0,
+						"deep": 1,
+					},
+				},
+			},
+			expected: `main_error:
+    message: error with nested tags
+    type: none
+    tags:
+        user:
+            id: 1
+            roles:
+                - admin
+                -
+                    - 2
+            empty: []
+        deep:
+            -
+                -
+                    -
+                        -
+                            -
+                                -
+                                    -
+                                        - [truncated]`,
+		},
 		{
 			label: "empty",
 			formatter: ErrorVerbosePrinter{
@@ -467,6 +510,11 @@ func TestBuildJsonStringOfType(t *testing.T) {
 			errType:  ErrorTypeNone,
 			expected: `"type":"none"`,
 		},
+		{
+			label:    "error type with quotes",
+			errType:  ErrorType(`say "hi"`),
+			expected: `"type":"say \"hi\""`,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -551,3 +599,34 @@ func TestBuildJsonStringOfSubErrors(t *testing.T) {
 		})
 	}
 }
+
+func FuzzToJsonString(f *testing.F) {
+	f.Add("message", "type", "req-123", "key", "value", 3.14, "main.go", "main.main")
+	f.Add("line1\nline2", `"quoted"`, "<req>", `C:\path\"x"`, "\x00\xff", math.NaN(), `C:\src\main.go`, "pkg.F[...]")
+	f.Add("", "", "", "", "", math.Inf(1), "", "")
+	f.Fuzz(func(t *testing.T, message, errType, requestID, tagKey, tagValue string, floatValue float64, file, function string) {
+		sub := NewRawStructuredError(errors.New(tagValue))
+		_ = sub.SetType(ErrorType(tagKey))
+		fe := &StructuredError{
+			errorType: ErrorType(errType),
+			err:       errors.New(message),
+			stacktrace: StackTrace{
+				{File: file, Line: 1, Function: function},
+			},
+			requestId: requestID,
+			tags:      NewTags(),
+			subErrors: []error{sub, errors.New(message)},
+		}
+		_ = fe.AddTagString(tagKey, tagValue)
+		_ = fe.AddTagFloat(tagKey+"_float", floatValue)
+		_ = fe.AddTagString(tagValue, tagKey)
+
+		got := ToJsonString(fe)
+		if !json.Valid([]byte(got)) {
+			t.Fatalf("invalid JSON: %s", got)
+		}
+		if _, err := FromJSON([]byte(got)); err != nil {
+			t.Fatalf("failed to parse JSON %s: %v", got, err)
+		}
+	})
+}
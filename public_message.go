@@ -0,0 +1,77 @@
+package serrors
+
+import (
+	"sync"
+)
+
+// DefaultPublicMessageStr is returned by PublicMessage() if neither public message nor default of the error type is found
+const DefaultPublicMessageStr string = "internal error"
+
+var defaultPublicMessages sync.Map // ErrorType -> string
+
+// SetDefaultPublicMessage sets the public message of errors of type t which have no public message
+// if message is empty, the default of t is removed.
+func SetDefaultPublicMessage(t ErrorType, message string) {
+	if message == "" {
+		defaultPublicMessages.Delete(t)
+		return
+	}
+	defaultPublicMessages.Store(t, message)
+}
+
+// DefaultPublicMessage returns the default public message of t set by SetDefaultPublicMessage()
+// if it is not set, PublicMessage of TypeInfo registered by RegisterType() is returned.
+func DefaultPublicMessage(t ErrorType) (string, bool) {
+	if v, ok := defaultPublicMessages.Load(t); ok {
+		return v.(string), true
+	}
+	if info, ok := LookupType(t); ok && info.PublicMessage != "" {
+		return info.PublicMessage, true
+	}
+	return "", false
+}
+
+// PublicMessage returns the message of err safe to show to users
+// It walks the wrapped errors of err and returns the outermost public message set by SetPublicMessage().
+// if none is set, it returns the default of the outermost error type having one, otherwise DefaultPublicMessageStr.
+// sub errors are not looked into.
+// if err is nil, it returns an empty string.
+func PublicMessage(err error) string {
+	if err == nil {
+		return ""
+	}
+	if message, ok := findPublicMessage(err); ok {
+		return message
+	}
+	if message, ok := findDefaultPublicMessage(err); ok {
+		return message
+	}
+	if message, ok := DefaultPublicMessage(ErrorTypeNone); ok {
+		return message
+	}
+	return DefaultPublicMessageStr
+}
+
+func findPublicMessage(err error) (string, bool) {
+	message := ""
+	found := WalkChain(err, func(e error) bool {
+		if pm, ok := e.(HasPublicMessage); ok && pm.PublicMessage() != "" {
+			message = pm.PublicMessage()
+			return true
+		}
+		return false
+	})
+	return message, found
+}
+
+func findDefaultPublicMessage(err error) (string, bool) {
+	message := ""
+	found := WalkChain(err, func(e error) bool {
+		if ht, ok := e.(HasType); ok && ht.Type() != ErrorTypeNone {
+			message, ok = DefaultPublicMessage(ht.Type())
+			return ok
+		}
+		return false
+	})
+	return message, found
+}
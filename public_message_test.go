@@ -0,0 +1,143 @@
+package serrors
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestPublicMessage(t *testing.T) {
+	SetDefaultPublicMessage("notFound", "resource not found")
+	SetDefaultPublicMessage("invalid", "invalid request")
+	defer SetDefaultPublicMessage("notFound", "")
+	defer SetDefaultPublicMessage("invalid", "")
+
+	withPublic := func(t ErrorType, message string, public string) error {
+		fe := NewRawStructuredError(errors.New(message))
+		_ = fe.SetType(t)
+		_ = fe.SetPublicMessage(public)
+		return fe
+	}
+
+	testCases := []struct {
+		label    string
+		err      error
+		expected string
+	}{
+		{
+			label:    "nil error",
+			err:      nil,
+			expected: "",
+		},
+		{
+			label:    "standard error",
+			err:      errors.New("dial tcp 10.0.0.1:5432: connection refused"),
+			expected: DefaultPublicMessageStr,
+		},
+		{
+			label:    "public message set",
+			err:      withPublic("notFound", "user 42 not found in db", "user not found"),
+			expected: "user not found",
+		},
+		{
+			label:    "default of the error type",
+			err:      withPublic("notFound", "user 42 not found in db", ""),
+			expected: "resource not found",
+		},
+		{
+			label:    "type without default",
+			err:      withPublic("unknown", "boom", ""),
+			expected: DefaultPublicMessageStr,
+		},
+		{
+			label:    "outermost public message wins",
+			err:      fmt.Errorf("handler: %w", withPublic("invalid", "outer", "outer public")),
+			expected: "outer public",
+		},
+		{
+			label: "wrapped by Builder",
+			err: Builder(withPublic("notFound", "inner", "inner public")).
+				PublicMessage("outer public").Build(),
+			expected: "outer public",
+		},
+		{
+			label:    "inner public message is used before default of outer type",
+			err:      fmt.Errorf("wrap: %w", withPublic("invalid", "inner", "inner public")),
+			expected: "inner public",
+		},
+		{
+			label:    "default of the outermost type",
+			err:      NewRawStructuredError(withPublic("notFound", "inner", "")).SetType("invalid"),
+			expected: "invalid request",
+		},
+		{
+			label:    "joined errors",
+			err:      errors.Join(errors.New("internal"), withPublic("", "inner", "joined public")),
+			expected: "joined public",
+		},
+		{
+			label: "sub errors are not looked into",
+			err: NewRawStructuredError(errors.New("main")).
+				AddSubError(withPublic("notFound", "sub", "sub public")),
+			expected: DefaultPublicMessageStr,
+		},
+		{
+			label:    "WithPublicMessage",
+			err:      With(errors.New("internal"), WithPublicMessage("with public")),
+			expected: "with public",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.label, func(t *testing.T) {
+			got := PublicMessage(tc.err)
+			if got != tc.expected {
+				t.Errorf("expected %q, got %q", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestSetDefaultPublicMessage(t *testing.T) {
+	SetDefaultPublicMessage(ErrorTypeNone, "please try again later")
+	defer SetDefaultPublicMessage(ErrorTypeNone, "")
+
+	if got := PublicMessage(errors.New("internal")); got != "please try again later" {
+		t.Errorf("expected default of none type, got %q", got)
+	}
+
+	SetDefaultPublicMessage(ErrorTypeNone, "")
+	if _, ok := DefaultPublicMessage(ErrorTypeNone); ok {
+		t.Errorf("expected default to be removed")
+	}
+	if got := PublicMessage(errors.New("internal")); got != DefaultPublicMessageStr {
+		t.Errorf("expected %q, got %q", DefaultPublicMessageStr, got)
+	}
+}
+
+func TestPublicMessage_Print(t *testing.T) {
+	fe := NewRawStructuredError(errors.New("user 42 not found in db"))
+	_ = fe.SetType("notFound")
+	_ = fe.SetRequestID("req-1")
+	_ = fe.SetPublicMessage("user not found")
+
+	expectedJson := `{"type":"notFound","message":"user 42 not found in db","request_id":"req-1","public_message":"user not found","stacktrace":[]}`
+	if got := fe.JsonString(); got != expectedJson {
+		t.Errorf("expected %s, got %s", expectedJson, got)
+	}
+	if got := fe.Error(); got != "[Type: notFound] user 42 not found in db" {
+		t.Errorf("Error() should keep the internal message, got %s", got)
+	}
+	if got := fmt.Sprintf("%+v", fe); !strings.Contains(got, "\n    public_message: user not found") {
+		t.Errorf("expected public_message in verbose output, got %s", got)
+	}
+}
+
+func TestBuildJsonStringOfPublicMessage(t *testing.T) {
+	got := BuildJsonStringOfPublicMessage(`user "42" not found`)
+	expected := `"public_message":"user \"42\" not found"`
+	if got != expected {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+}
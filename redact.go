@@ -0,0 +1,160 @@
+package serrors
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"sync/atomic"
+)
+
+const RedactedStr string = "[REDACTED]"
+
+// Redactor decides how each tag is printed by ErrorJsonPrinter and ErrorVerbosePrinter
+// Redact returns the value to print instead of value, or value itself if it is not redacted.
+// key is the key of the tag. For items of ArrayTagValue, it is the key of the array.
+type Redactor interface {
+	Redact(key string, value TagValue) TagValue
+}
+
+type RedactorFunc func(key string, value TagValue) TagValue
+
+func (f RedactorFunc) Redact(key string, value TagValue) TagValue {
+	return f(key, value)
+}
+
+type redactorHolder struct {
+	redactor Redactor
+}
+
+var defaultRedactor atomic.Value
+
+// SetDefaultRedactor sets Redactor used by printers which have no Redactor set by WithRedactor()
+// if r is nil, only SecretTagValue is redacted.
+func SetDefaultRedactor(r Redactor) {
+	defaultRedactor.Store(redactorHolder{redactor: r})
+}
+
+func DefaultRedactor() Redactor {
+	holder, _ := defaultRedactor.Load().(redactorHolder)
+	return holder.redactor
+}
+
+// Redactors chains redactors in order
+func Redactors(redactors ...Redactor) Redactor {
+	return RedactorFunc(func(key string, value TagValue) TagValue {
+		for _, r := range redactors {
+			if r != nil {
+				value = r.Redact(key, value)
+			}
+		}
+		return value
+	})
+}
+
+// KeyRedactor redacts tags whose key matches any of patterns
+// patterns are case-insensitive and '*' matches any sequence of characters.
+// e.g. "*password*", "authorization"
+func KeyRedactor(patterns ...string) Redactor {
+	lowered := make([]string, 0, len(patterns))
+	for _, p := range patterns {
+		lowered = append(lowered, strings.ToLower(p))
+	}
+	return RedactorFunc(func(key string, value TagValue) TagValue {
+		if _, ok := value.(SecretTagValue); ok {
+			return value
+		}
+		lowerKey := strings.ToLower(key)
+		for _, p := range lowered {
+			if matchWildcard(p, lowerKey) {
+				return SecretTagValue{Value: value}
+			}
+		}
+		return value
+	})
+}
+
+// HashRedactor prints SecretTagValue as a salted SHA-256 hash prefix like "sha256:1a2b3c4d"
+// It makes possible to check whether two logs have the same secret without leaking it.
+// Combine with KeyRedactor to hash tags matching patterns: Redactors(KeyRedactor("*email*"), HashRedactor(salt, 8))
+func HashRedactor(salt string, prefixLen int) Redactor {
+	if prefixLen <= 0 || prefixLen > sha256.Size*2 {
+		prefixLen = sha256.Size * 2
+	}
+	return RedactorFunc(func(key string, value TagValue) TagValue {
+		secret, ok := value.(SecretTagValue)
+		if !ok {
+			return value
+		}
+		plain := ""
+		if secret.Value != nil {
+			plain = secret.Value.String()
+		}
+		sum := sha256.Sum256([]byte(salt + plain))
+		return StringTagValue("sha256:" + hex.EncodeToString(sum[:])[:prefixLen])
+	})
+}
+
+// matchWildcard matches s with pattern where '*' matches any sequence of characters
+func matchWildcard(pattern, s string) bool {
+	p, i := 0, 0
+	star, mark := -1, 0
+	for i < len(s) {
+		switch {
+		case p < len(pattern) && pattern[p] == '*':
+			star = p
+			mark = i
+			p++
+		case p < len(pattern) && pattern[p] == s[i]:
+			p++
+			i++
+		case star >= 0:
+			p = star + 1
+			mark++
+			i = mark
+		default:
+			return false
+		}
+	}
+	for p < len(pattern) && pattern[p] == '*' {
+		p++
+	}
+	return p == len(pattern)
+}
+
+// redactTags returns a copy of tags redacted by r
+// ObjectTagValue and ArrayTagValue are redacted recursively. raw JsonTagValue is not looked into.
+func redactTags(tags Tags, r Redactor) Tags {
+	return redactTagsDepth(tags, r, 1)
+}
+
+func redactTagsDepth(tags Tags, r Redactor, depth int) Tags {
+	if r == nil || len(tags.tags) == 0 {
+		return tags
+	}
+	redacted := Tags{
+		tags:   make([]Tag, len(tags.tags)),
+		keyMap: tags.keyMap,
+	}
+	for i, tag := range tags.tags {
+		redacted.tags[i] = Tag{Key: tag.Key, Value: redactTagValue(tag.Key, tag.Value, r, depth)}
+	}
+	return redacted
+}
+
+func redactTagValue(key string, value TagValue, r Redactor, depth int) TagValue {
+	value = r.Redact(key, value)
+	if depth > MaxTagValueDepth {
+		return value
+	}
+	switch v := value.(type) {
+	case ObjectTagValue:
+		return ObjectTagValue(redactTagsDepth(Tags(v), r, depth+1))
+	case ArrayTagValue:
+		redacted := make(ArrayTagValue, len(v))
+		for i, item := range v {
+			redacted[i] = redactTagValue(key, item, r, depth+1)
+		}
+		return redacted
+	}
+	return value
+}
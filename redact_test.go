@@ -0,0 +1,197 @@
+package serrors
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestMatchWildcard(t *testing.T) {
+	testCases := []struct {
+		pattern  string
+		s        string
+		expected bool
+	}{
+		{pattern: "authorization", s: "authorization", expected: true},
+		{pattern: "authorization", s: "authorization2", expected: false},
+		{pattern: "*password*", s: "password", expected: true},
+		{pattern: "*password*", s: "db_password_hash", expected: true},
+		{pattern: "*password*", s: "passwd", expected: false},
+		{pattern: "*_token", s: "access_token", expected: true},
+		{pattern: "*_token", s: "access_token_id", expected: false},
+		{pattern: "a*b*c", s: "aXXbYYc", expected: true},
+		{pattern: "a*b*c", s: "aXXcYYb", expected: false},
+		{pattern: "*", s: "", expected: true},
+		{pattern: "", s: "", expected: true},
+		{pattern: "", s: "a", expected: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.pattern+" "+tc.s, func(t *testing.T) {
+			if got := matchWildcard(tc.pattern, tc.s); got != tc.expected {
+				t.Errorf("expected %v, got %v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func newTestRedactError() *StructuredError {
+	fe := NewRawStructuredError(errors.New("login failed"))
+	_ = fe.AddTagString("user", "alice")
+	_ = fe.AddTagSensitive("email", "alice@example.com")
+	_ = fe.AddTagString("Authorization", "Bearer abc")
+	inner := NewTags()
+	inner.SetValue("db_password", "secret")
+	inner.SetValue("host", "localhost")
+	_ = fe.AddTagSafe("config", ObjectTagValue(inner))
+	_ = fe.AddTagSafe("api_tokens", ArrayTagValue{StringTagValue("t1"), StringTagValue("t2")})
+	return fe
+}
+
+func TestErrorJsonPrinter_Redact(t *testing.T) {
+	hash := sha256.Sum256([]byte("salt" + "alice@example.com"))
+	hashed := "sha256:" + hex.EncodeToString(hash[:])[:8]
+
+	testCases := []struct {
+		label    string
+		redactor Redactor
+		expected string
+	}{
+		{
+			label:    "secret is redacted by default",
+			redactor: nil,
+			expected: `{"type":"none","message":"login failed","tags":{"user":"alice","email":"[REDACTED]","Authorization":"Bearer abc","config":{"db_password":"secret","host":"localhost"},"api_tokens":["t1","t2"]},"stacktrace":[]}`,
+		},
+		{
+			label:    "key redactor",
+			redactor: KeyRedactor("*password*", "authorization", "*_tokens"),
+			expected: `{"type":"none","message":"login failed","tags":{"user":"alice","email":"[REDACTED]","Authorization":"[REDACTED]","config":{"db_password":"[REDACTED]","host":"localhost"},"api_tokens":"[REDACTED]"},"stacktrace":[]}`,
+		},
+		{
+			label:    "hash redactor",
+			redactor: HashRedactor("salt", 8),
+			expected: `{"type":"none","message":"login failed","tags":{"user":"alice","email":"` + hashed + `","Authorization":"Bearer abc","config":{"db_password":"secret","host":"localhost"},"api_tokens":["t1","t2"]},"stacktrace":[]}`,
+		},
+		{
+			label: "redactor func",
+			redactor: RedactorFunc(func(key string, value TagValue) TagValue {
+				if key == "user" {
+					return StringTagValue("a***")
+				}
+				return value
+			}),
+			expected: `{"type":"none","message":"login failed","tags":{"user":"a***","email":"[REDACTED]","Authorization":"Bearer abc","config":{"db_password":"secret","host":"localhost"},"api_tokens":["t1","t2"]},"stacktrace":[]}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.label, func(t *testing.T) {
+			got := newTestRedactError().errorJsonPrinter().WithRedactor(tc.redactor).Print()
+			if got != tc.expected {
+				t.Errorf("expected %s, got %s", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestErrorJsonPrinter_RedactSubErrors(t *testing.T) {
+	sub := NewRawStructuredError(errors.New("sub"))
+	_ = sub.AddTagString("password", "p")
+	fe := NewRawStructuredError(errors.New("main"))
+	_ = fe.AddSubError(sub)
+
+	got := fe.errorJsonPrinter().WithRedactor(KeyRedactor("password")).Print()
+	expected := `{"type":"none","message":"main","stacktrace":[],"sub_errors":[{"type":"none","message":"sub","tags":{"password":"[REDACTED]"},"stacktrace":[]}]}`
+	if got != expected {
+		t.Errorf("expected %s, got %s", expected, got)
+	}
+}
+
+func TestErrorVerbosePrinter_Redact(t *testing.T) {
+	sub := NewRawStructuredError(errors.New("sub"))
+	_ = sub.AddTagString("password", "p")
+	fe := newTestRedactError()
+	_ = fe.AddSubError(sub)
+
+	got := fe.VerbosePrinter().(ErrorVerbosePrinter).WithRedactor(KeyRedactor("*password*")).Print()
+	expected := `main_error:
+    message: login failed
+    type: none
+    tags:
+        user: alice
+        email: [REDACTED]
+        Authorization: Bearer abc
+        config:
+            db_password: [REDACTED]
+            host: localhost
+        api_tokens:
+            - t1
+            - t2
+main_error.sub1:
+    message: sub
+    type: none
+    tags:
+        password: [REDACTED]`
+	if got != expected {
+		t.Errorf("expected %s, got %s", expected, got)
+	}
+}
+
+func TestSetDefaultRedactor(t *testing.T) {
+	SetDefaultRedactor(KeyRedactor("user"))
+	t.Cleanup(func() {
+		SetDefaultRedactor(nil)
+	})
+
+	fe := newTestRedactError()
+	if got := fe.JsonString(); !strings.Contains(got, `"user":"[REDACTED]"`) {
+		t.Errorf("expected user to be redacted by default redactor, got %s", got)
+	}
+	if got := fe.errorJsonPrinter().WithRedactor(KeyRedactor("email")).Print(); !strings.Contains(got, `"user":"alice"`) {
+		t.Errorf("expected printer redactor to be used instead of default redactor, got %s", got)
+	}
+
+	buf := &bytes.Buffer{}
+	slog.New(slog.NewJSONHandler(buf, nil)).Info("msg", "err", fe)
+	if got := buf.String(); !strings.Contains(got, `"user":"[REDACTED]"`) || !strings.Contains(got, `"email":"[REDACTED]"`) {
+		t.Errorf("expected tags to be redacted in slog, got %s", got)
+	}
+}
+
+func TestSetDefaultRedactor_AnyValueTag(t *testing.T) {
+	SetDefaultRedactor(KeyRedactor("*password*"))
+	t.Cleanup(func() {
+		SetDefaultRedactor(nil)
+	})
+
+	type credential struct {
+		User     string `json:"user"`
+		Password string `json:"password"`
+	}
+	testCases := []struct {
+		label string
+		value any
+	}{
+		{label: "map", value: map[string]any{"password": "hunter2"}},
+		{label: "struct", value: credential{User: "alice", Password: "hunter2"}},
+		{label: "slice of maps", value: []map[string]string{{"password": "hunter2"}}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.label, func(t *testing.T) {
+			fe := NewRawStructuredError(errors.New("login failed"))
+			_ = fe.AddTag("login", tc.value)
+			if got := fe.JsonString(); strings.Contains(got, "hunter2") || !strings.Contains(got, `"password":"[REDACTED]"`) {
+				t.Errorf("expected password to be redacted in JSON, got %s", got)
+			}
+			if got := fmt.Sprintf("%+v", fe); strings.Contains(got, "hunter2") || !strings.Contains(got, "password: [REDACTED]") {
+				t.Errorf("expected password to be redacted in verbose output, got %s", got)
+			}
+		})
+	}
+}
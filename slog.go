@@ -0,0 +1,133 @@
+package serrors
+
+import (
+	"log/slog"
+	"strconv"
+	"time"
+)
+
+// LogValue implements slog.LogValuer
+// The group has the same items as JsonString()
+func (e *StructuredError) LogValue() slog.Value {
+	if e == nil {
+		return slog.AnyValue(nil)
+	}
+	return e.errorJsonPrinter().LogValue()
+}
+
+func (f ErrorJsonPrinter) LogValue() slog.Value {
+	attrs := make([]slog.Attr, 0, 9)
+	attrs = append(attrs, slog.String("type", f.errorType.StringWithDefaultNone()))
+	if f.err == nil {
+		attrs = append(attrs, slog.String("message", NoErrStr))
+	} else {
+		attrs = append(attrs, slog.String("message", scrubMessage(f.err.Error(), f.options.activeScrubber())))
+	}
+	if f.when != nil {
+		attrs = append(attrs, slog.Time("when", *f.when))
+	}
+	if f.requestId != "" {
+		attrs = append(attrs, slog.String("request_id", f.requestId))
+	}
+	if f.publicMessage != "" {
+		attrs = append(attrs, slog.String("public_message", f.publicMessage))
+	}
+	if f.origin != "" {
+		attrs = append(attrs, slog.String("origin", f.origin))
+	}
+	if len(f.tags.tags) > 0 {
+		attrs = append(attrs, slog.Attr{Key: "tags", Value: redactTags(f.tags, f.options.activeRedactor()).LogValue()})
+	}
+	attrs = append(attrs, slog.Any("stacktrace", f.stacktrace.nonNil()))
+	if len(f.subErrors) > 0 {
+		subAttrs := make([]slog.Attr, 0, len(f.subErrors))
+		for i, subErr := range f.subErrors {
+			if subErr == nil {
+				continue
+			}
+			subAttrs = append(subAttrs, slog.Attr{Key: strconv.Itoa(i), Value: errorLogValue(subErr, f.options)})
+		}
+		attrs = append(attrs, slog.Attr{Key: "sub_errors", Value: slog.GroupValue(subAttrs...)})
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// ErrorLogValue converts any error into slog.Value
+// errors which are not slog.LogValuer are printed as errors with type none.
+func ErrorLogValue(err error) slog.Value {
+	return errorLogValue(err, printOptions{})
+}
+
+// errorLogValue converts err into slog.Value passing options to ErrorJsonPrinter
+func errorLogValue(err error, options printOptions) slog.Value {
+	if fe, ok := err.(HasJsonPrinter); ok {
+		if jp, ok := fe.JsonPrinter().(ErrorJsonPrinter); ok {
+			jp.options = jp.options.inherit(options)
+			return jp.LogValue()
+		}
+	}
+	if lv, ok := err.(slog.LogValuer); ok {
+		return lv.LogValue()
+	}
+	return ErrorJsonPrinter{errorType: ErrorTypeNone, err: err, options: options}.LogValue()
+}
+
+// LogValue converts tags into slog group keeping the order of tags
+func (tags Tags) LogValue() slog.Value {
+	return tags.logValueDepth(1)
+}
+
+func (tags Tags) logValueDepth(depth int) slog.Value {
+	attrs := make([]slog.Attr, 0, len(tags.tags))
+	for _, tag := range tags.tags {
+		attrs = append(attrs, slog.Attr{Key: tag.Key, Value: tagSlogValueDepth(tag.Value, depth)})
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// TagSlogValue converts TagValue into typed slog.Value
+// unknown TagValue is converted by slog.LogValuer if implemented, otherwise by String()
+func TagSlogValue(value TagValue) slog.Value {
+	return tagSlogValueDepth(value, 1)
+}
+
+func tagSlogValueDepth(value TagValue, depth int) slog.Value {
+	switch v := value.(type) {
+	case nil:
+		return slog.AnyValue(nil)
+	case StringTagValue:
+		return slog.StringValue(string(v))
+	case IntTagValue:
+		return slog.IntValue(int(v))
+	case FloatTagValue:
+		return slog.Float64Value(float64(v))
+	case BoolTagValue:
+		return slog.BoolValue(bool(v))
+	case NilTagValue:
+		return slog.AnyValue(nil)
+	case TimeTagValue:
+		return slog.TimeValue(v.Time)
+	case DurationTagValue:
+		return slog.DurationValue(time.Duration(v))
+	case SecretTagValue:
+		return slog.StringValue(v.String())
+	case ObjectTagValue:
+		if depth > MaxTagValueDepth {
+			return slog.StringValue(TruncatedTagValueStr)
+		}
+		return Tags(v).logValueDepth(depth + 1)
+	case ArrayTagValue, JsonTagValue:
+		// JSON handlers write it by MarshalJSON, text handlers by MarshalText
+		return slog.AnyValue(v)
+	case slog.LogValuer:
+		return v.LogValue()
+	}
+	return slog.StringValue(value.String())
+}
+
+func (st StackTrace) nonNil() StackTrace {
+	if st == nil {
+		return make(StackTrace, 0)
+	}
+	return st
+}
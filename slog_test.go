@@ -0,0 +1,100 @@
+package serrors
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStructuredError_LogValue(t *testing.T) {
+	tm := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	testCases := []struct {
+		label    string
+		err      error
+		expected string
+	}{
+		{
+			label:    "required fields",
+			err:      NewRawStructuredError(errors.New("test error")),
+			expected: `{"err":{"type":"none","message":"test error","stacktrace":[]}}`,
+		},
+		{
+			label: "all fields",
+			err: &StructuredError{
+				errorType: ErrorType("testType"),
+				err:       errors.New("test error"),
+				stacktrace: StackTrace{
+					{File: "example.go", Line: 10, Function: "main.exampleFunction"},
+				},
+				when:      &tm,
+				requestId: "req-123",
+				tags: Tags{
+					tags: []Tag{
+						{Key: "s", Value: StringTagValue("value")},
+						{Key: "i", Value: IntTagValue(42)},
+						{Key: "f", Value: FloatTagValue(3.14)},
+						{Key: "b", Value: BoolTagValue(true)},
+						{Key: "n", Value: NilTagValue{}},
+					},
+					keyMap: map[string]int{"s": 0, "i": 1, "f": 2, "b": 3, "n": 4},
+				},
+				subErrors: []error{
+					errors.New("sub error 1"),
+					&StructuredError{errorType: ErrorType("subType"), err: errors.New("sub error 2")},
+				},
+			},
+			expected: `{"err":{"type":"testType","message":"test error","when":"2024-01-01T12:00:00Z","request_id":"req-123","tags":{"s":"value","i":42,"f":3.14,"b":true,"n":null},"stacktrace":[{"file":"example.go","line":10,"function":"main.exampleFunction"}],"sub_errors":{"0":{"type":"none","message":"sub error 1","stacktrace":[]},"1":{"type":"subType","message":"sub error 2","stacktrace":[]}}}}`,
+		},
+		{
+			label:    "nil error",
+			err:      (*StructuredError)(nil),
+			expected: `{"err":null}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.label, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			logger := slog.New(slog.NewJSONHandler(buf, &slog.HandlerOptions{
+				ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+					if len(groups) == 0 && (a.Key == slog.TimeKey || a.Key == slog.LevelKey || a.Key == slog.MessageKey) {
+						return slog.Attr{}
+					}
+					return a
+				},
+			}))
+			logger.Info("", slog.Any("err", tc.err))
+			got := strings.TrimSpace(buf.String())
+			if got != tc.expected {
+				t.Errorf("expected %s, got %s", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestTagSlogValue(t *testing.T) {
+	testCases := []struct {
+		label    string
+		value    TagValue
+		expected slog.Kind
+	}{
+		{label: "string", value: StringTagValue("s"), expected: slog.KindString},
+		{label: "int", value: IntTagValue(1), expected: slog.KindInt64},
+		{label: "float", value: FloatTagValue(1.5), expected: slog.KindFloat64},
+		{label: "bool", value: BoolTagValue(true), expected: slog.KindBool},
+		{label: "nil", value: NilTagValue{}, expected: slog.KindAny},
+		{label: "nil interface", value: nil, expected: slog.KindAny},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.label, func(t *testing.T) {
+			got := TagSlogValue(tc.value)
+			if got.Kind() != tc.expected {
+				t.Errorf("expected kind %v, got %v", tc.expected, got.Kind())
+			}
+		})
+	}
+}
@@ -0,0 +1,145 @@
+// Package slogx provides slog.Handler which expands errors into structured groups
+package slogx
+
+import (
+	"context"
+	"log/slog"
+
+	serrors "github.com/hinoguma/go-structured-error"
+)
+
+// Options configures Handler
+type Options struct {
+	// RequestIDKey is the key of the top level attribute the request id of the first logged error is lifted to.
+	// it is written out of groups opened by WithGroup() too.
+	// if it is empty, request id is not lifted.
+	RequestIDKey string
+}
+
+// Handler wraps another slog.Handler
+// It expands every error attribute into a group by serrors.ToStructured() before passing it to the wrapped handler.
+type Handler struct {
+	next slog.Handler
+	opts Options
+
+	// base is next without groups opened by WithGroup() and attrs added in them.
+	// it writes the lifted request id at the top level while groups are open.
+	base   slog.Handler
+	groups []group
+}
+
+// group is a group opened by WithGroup() with attrs added in it by WithAttrs()
+type group struct {
+	name  string
+	attrs []slog.Attr
+}
+
+// NewHandler returns Handler wrapping next
+// if opts is nil, default options are used
+func NewHandler(next slog.Handler, opts *Options) *Handler {
+	h := &Handler{next: next, base: next}
+	if opts != nil {
+		h.opts = *opts
+	}
+	return h
+}
+
+func (h *Handler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *Handler) Handle(ctx context.Context, r slog.Record) error {
+	attrs := make([]slog.Attr, 0, r.NumAttrs())
+	requestID := ""
+	hasRequestID := false
+	r.Attrs(func(attr slog.Attr) bool {
+		if h.opts.RequestIDKey != "" && attr.Key == h.opts.RequestIDKey {
+			hasRequestID = true
+		}
+		attrs = append(attrs, h.expand(attr, &requestID))
+		return true
+	})
+
+	expanded := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+	if requestID == "" {
+		expanded.AddAttrs(attrs...)
+		return h.next.Handle(ctx, expanded)
+	}
+	if len(h.groups) == 0 {
+		expanded.AddAttrs(attrs...)
+		if !hasRequestID {
+			expanded.AddAttrs(slog.String(h.opts.RequestIDKey, requestID))
+		}
+		return h.next.Handle(ctx, expanded)
+	}
+	// attrs of the record are in the groups, so the request id is written by base outside them
+	expanded.AddAttrs(h.nest(attrs), slog.String(h.opts.RequestIDKey, requestID))
+	return h.base.Handle(ctx, expanded)
+}
+
+// nest puts attrs into the groups opened by WithGroup() as slog.Group attrs
+func (h *Handler) nest(attrs []slog.Attr) slog.Attr {
+	var nested slog.Attr
+	for i := len(h.groups) - 1; i >= 0; i-- {
+		g := h.groups[i]
+		groupAttrs := make([]slog.Attr, 0, len(g.attrs)+len(attrs))
+		groupAttrs = append(groupAttrs, g.attrs...)
+		groupAttrs = append(groupAttrs, attrs...)
+		nested = slog.Attr{Key: g.name, Value: slog.GroupValue(groupAttrs...)}
+		attrs = []slog.Attr{nested}
+	}
+	return nested
+}
+
+// WithAttrs expands errors in attrs too
+// request id is not lifted from attrs given here
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	expanded := make([]slog.Attr, 0, len(attrs))
+	for _, attr := range attrs {
+		expanded = append(expanded, h.expand(attr, nil))
+	}
+	next := h.next.WithAttrs(expanded)
+	if len(h.groups) == 0 {
+		return &Handler{next: next, opts: h.opts, base: next}
+	}
+	groups := make([]group, len(h.groups))
+	copy(groups, h.groups)
+	last := &groups[len(groups)-1]
+	last.attrs = append(last.attrs[:len(last.attrs):len(last.attrs)], expanded...)
+	return &Handler{next: next, opts: h.opts, base: h.base, groups: groups}
+}
+
+func (h *Handler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	groups := make([]group, len(h.groups), len(h.groups)+1)
+	copy(groups, h.groups)
+	groups = append(groups, group{name: name})
+	return &Handler{next: h.next.WithGroup(name), opts: h.opts, base: h.base, groups: groups}
+}
+
+// expand replaces error values in attr with structured groups
+// requestID is set to the request id of the first error having it if requestID is not nil
+func (h *Handler) expand(attr slog.Attr, requestID *string) slog.Attr {
+	switch attr.Value.Kind() {
+	case slog.KindAny, slog.KindLogValuer:
+		err, ok := attr.Value.Any().(error)
+		if !ok || err == nil {
+			return attr
+		}
+		fe := serrors.ToStructured(err)
+		if requestID != nil && *requestID == "" && h.opts.RequestIDKey != "" {
+			*requestID = fe.RequestID()
+		}
+		return slog.Attr{Key: attr.Key, Value: serrors.ErrorLogValue(fe)}
+	case slog.KindGroup:
+		group := attr.Value.Group()
+		expanded := make([]slog.Attr, 0, len(group))
+		for _, child := range group {
+			expanded = append(expanded, h.expand(child, requestID))
+		}
+		return slog.Attr{Key: attr.Key, Value: slog.GroupValue(expanded...)}
+	}
+	return attr
+}
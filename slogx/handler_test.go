@@ -0,0 +1,137 @@
+package slogx
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+
+	serrors "github.com/hinoguma/go-structured-error"
+)
+
+func newTestLogger(buf *bytes.Buffer, opts *Options) *slog.Logger {
+	return slog.New(NewHandler(slog.NewJSONHandler(buf, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if len(groups) == 0 && (a.Key == slog.TimeKey || a.Key == slog.LevelKey) {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}), opts))
+}
+
+func TestHandler_Handle(t *testing.T) {
+	withRequestID := serrors.Builder(errors.New("structured error")).
+		Type("testType").
+		RequestID("req-123").
+		Build()
+
+	testCases := []struct {
+		label    string
+		opts     *Options
+		log      func(logger *slog.Logger)
+		expected string
+	}{
+		{
+			label: "standard error is expanded",
+			opts:  nil,
+			log: func(logger *slog.Logger) {
+				logger.Info("msg", "err", errors.New("standard error"))
+			},
+			expected: `{"msg":"msg","err":{"type":"none","message":"standard error","stacktrace":[]}}`,
+		},
+		{
+			label: "wrapped structured error is expanded",
+			opts:  nil,
+			log: func(logger *slog.Logger) {
+				logger.Info("msg", "err", fmt.Errorf("wrap: %w", errors.New("inner")))
+			},
+			expected: `{"msg":"msg","err":{"type":"none","message":"wrap: inner","stacktrace":[]}}`,
+		},
+		{
+			label: "error in group is expanded",
+			opts:  nil,
+			log: func(logger *slog.Logger) {
+				logger.Info("msg", slog.Group("g", "err", errors.New("standard error")))
+			},
+			expected: `{"msg":"msg","g":{"err":{"type":"none","message":"standard error","stacktrace":[]}}}`,
+		},
+		{
+			label: "non error attrs are kept",
+			opts:  nil,
+			log: func(logger *slog.Logger) {
+				logger.Info("msg", "key", "value", "n", 1)
+			},
+			expected: `{"msg":"msg","key":"value","n":1}`,
+		},
+		{
+			label: "request id is lifted",
+			opts:  &Options{RequestIDKey: "request_id"},
+			log: func(logger *slog.Logger) {
+				logger.Info("msg", "err", withRequestID)
+			},
+			expected: `{"msg":"msg","err":{"type":"testType","message":"structured error","request_id":"req-123","stacktrace":[]},"request_id":"req-123"}`,
+		},
+		{
+			label: "request id is not lifted if already logged",
+			opts:  &Options{RequestIDKey: "request_id"},
+			log: func(logger *slog.Logger) {
+				logger.Info("msg", "request_id", "req-999", "err", withRequestID)
+			},
+			expected: `{"msg":"msg","request_id":"req-999","err":{"type":"testType","message":"structured error","request_id":"req-123","stacktrace":[]}}`,
+		},
+		{
+			label: "request id is not lifted without option",
+			opts:  nil,
+			log: func(logger *slog.Logger) {
+				logger.Info("msg", "err", withRequestID)
+			},
+			expected: `{"msg":"msg","err":{"type":"testType","message":"structured error","request_id":"req-123","stacktrace":[]}}`,
+		},
+		{
+			label: "request id is lifted to the top level out of groups",
+			opts:  &Options{RequestIDKey: "request_id"},
+			log: func(logger *slog.Logger) {
+				logger.With("a", 1).WithGroup("http").With("method", "GET").WithGroup("route").Info("msg", "err", withRequestID)
+			},
+			expected: `{"msg":"msg","a":1,"http":{"method":"GET","route":{"err":{"type":"testType","message":"structured error","request_id":"req-123","stacktrace":[]}}},"request_id":"req-123"}`,
+		},
+		{
+			label: "request id in groups does not stop lifting",
+			opts:  &Options{RequestIDKey: "request_id"},
+			log: func(logger *slog.Logger) {
+				logger.WithGroup("http").Info("msg", "request_id", "req-999", "err", withRequestID)
+			},
+			expected: `{"msg":"msg","http":{"request_id":"req-999","err":{"type":"testType","message":"structured error","request_id":"req-123","stacktrace":[]}},"request_id":"req-123"}`,
+		},
+		{
+			label: "groups without request id are kept",
+			opts:  &Options{RequestIDKey: "request_id"},
+			log: func(logger *slog.Logger) {
+				logger.WithGroup("http").Info("msg", "k", "v")
+			},
+			expected: `{"msg":"msg","http":{"k":"v"}}`,
+		},
+		{
+			label: "errors in WithAttrs are expanded",
+			opts:  nil,
+			log: func(logger *slog.Logger) {
+				logger.With("err", errors.New("standard error")).WithGroup("g").Info("msg", "k", "v")
+			},
+			expected: `{"msg":"msg","err":{"type":"none","message":"standard error","stacktrace":[]},"g":{"k":"v"}}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.label, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			tc.log(newTestLogger(buf, tc.opts))
+			got := strings.TrimSpace(buf.String())
+			if got != tc.expected {
+				t.Errorf("expected %s, got %s", tc.expected, got)
+			}
+		})
+	}
+}
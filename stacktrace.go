@@ -4,24 +4,31 @@ import (
 	"fmt"
 	"runtime"
 	"strconv"
+	"sync"
 )
 
 type StackTrace []StackTraceItem
 
 func (st StackTrace) JsonValueString() string {
-	jv := "["
+	return string(st.AppendJSON(nil))
+}
+
+// AppendJSON appends the same JSON as JsonValueString() to dst
+func (st StackTrace) AppendJSON(dst []byte) []byte {
+	dst = append(dst, '[')
 	for i, item := range st {
 		if i > 0 {
-			jv += JsonItemSeparator
+			dst = append(dst, JsonItemSeparator...)
 		}
-		jv += "{"
-		jv += fmt.Sprintf(`"file":"%s"%s`, item.File, JsonItemSeparator)
-		jv += fmt.Sprintf(`"line":%s%s`, strconv.Itoa(item.Line), JsonItemSeparator)
-		jv += fmt.Sprintf(`"function":"%s"`, item.Function)
-		jv += "}"
+		dst = append(dst, `{"file":`...)
+		dst = appendJsonString(dst, item.File)
+		dst = append(dst, `,"line":`...)
+		dst = strconv.AppendInt(dst, int64(item.Line), 10)
+		dst = append(dst, `,"function":`...)
+		dst = appendJsonString(dst, item.Function)
+		dst = append(dst, '}')
 	}
-	jv += "]"
-	return jv
+	return append(dst, ']')
 }
 
 // NewStackTrace captures the current stack trace
@@ -33,23 +40,55 @@ func NewStackTrace(skip int, maxDepth int) StackTrace {
 	if skip < 0 {
 		skip = 0
 	}
-	skip += 2 // skip Callers and NewStackTrace
+	return newCallers(skip+1, maxDepth).StackTrace() // skip +1 to skip NewStackTrace
+}
+
+// callers holds raw program counters of a captured stack.
+// resolving program counters into frames is expensive,
+// so it is deferred until the stack trace is actually needed and done only once.
+type callers struct {
+	pcs    []uintptr
+	once   sync.Once
+	frames StackTrace
+}
+
+// newCallers captures program counters starting from the caller of newCallers()
+// skip and maxDepth work same as NewStackTrace()
+func newCallers(skip int, maxDepth int) *callers {
+	if skip < 0 {
+		skip = 0
+	}
+	skip += 2 // skip Callers and newCallers
 	if maxDepth <= 0 {
-		return make(StackTrace, 0)
+		return &callers{}
 	}
-	var trace StackTrace
 	pc := make([]uintptr, maxDepth)
 	cnt := runtime.Callers(skip, pc)
-	frames := runtime.CallersFrames(pc[:cnt])
-	for {
-		frame, more := frames.Next()
-		item := NewStackTraceItem(frame)
-		trace = append(trace, item)
-		if !more {
-			break
+	return &callers{pcs: pc[:cnt]}
+}
+
+// StackTrace resolves program counters into StackTrace
+// It is safe to call concurrently.
+func (c *callers) StackTrace() StackTrace {
+	c.once.Do(func() {
+		c.frames = make(StackTrace, 0, len(c.pcs))
+		if len(c.pcs) == 0 {
+			return
 		}
-	}
-	return trace
+		frames := runtime.CallersFrames(c.pcs)
+		for {
+			frame, more := frames.Next()
+			c.frames = append(c.frames, NewStackTraceItem(frame))
+			if !more {
+				break
+			}
+		}
+	})
+	return c.frames
+}
+
+func (c *callers) len() int {
+	return len(c.pcs)
 }
 
 type StackTraceItem struct {
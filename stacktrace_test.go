@@ -286,6 +286,17 @@ func TestStackTrace_JsonValueString(t *testing.T) {
 			},
 			expected: `[{"file":"file1.go","line":10,"function":"function1"},{"file":"file2.go","line":20,"function":"function2"}]`,
 		},
+		{
+			label: "windows path and generic function",
+			trace: StackTrace{
+				{
+					File:     `C:\work\"src"\main.go`,
+					Line:     30,
+					Function: "main.F[...]",
+				},
+			},
+			expected: `[{"file":"C:\\work\\\"src\"\\main.go","line":30,"function":"main.F[...]"}]`,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -3,6 +3,8 @@ package serrors
 import (
 	"errors"
 	"fmt"
+	"io"
+	"reflect"
 	"time"
 )
 
@@ -28,6 +30,16 @@ func NewWithSkipAndDepth(err error, skip int, maxDepth int) *StructuredError {
 	return fe
 }
 
+// clone returns a shallow copy of e which can be changed without changing e
+// the main error, sub errors and stack trace are shared.
+func (e *StructuredError) clone() *StructuredError {
+	cloned := *e
+	cloned.tags = e.tags.clone()
+	cloned.subErrors = make([]error, len(e.subErrors))
+	copy(cloned.subErrors, e.subErrors)
+	return &cloned
+}
+
 const NoErrStr string = "<no error>"
 
 const ErrorTypeNone ErrorType = ""
@@ -55,12 +67,21 @@ type StructuredError struct {
 	errorType  ErrorType
 	err        error
 	stacktrace StackTrace
+	// callers is captured program counters resolved into stacktrace lazily
+	// if callers is set, it takes precedence over stacktrace
+	callers *callers
 
 	// optional
 	when      *time.Time
 	requestId string
 	tags      Tags
 	subErrors []error
+	// publicMessage is the message safe to show to users, e.g. API clients
+	publicMessage string
+	// template is set if the error is created by Template
+	template *Template
+	// origin is the name of the service the error is decoded from by DecodeWire
+	origin string
 }
 
 func (e *StructuredError) Error() string {
@@ -71,32 +92,116 @@ func (e *StructuredError) Error() string {
 	return fmt.Sprintf("[Type: %s] %s", e.errorType.StringWithDefaultNone(), m)
 }
 
+// Unwrap returns the main error only for compatibility with errors.Unwrap() and SError.
+// sub errors are reached by errors.Is() and errors.As() through Is() and As() methods,
+// and by SubErrors().
 func (e StructuredError) Unwrap() error {
 	return e.err
 }
 
+// SubErrors returns sub errors added by AddSubError()
+func (e StructuredError) SubErrors() []error {
+	subErrors := make([]error, len(e.subErrors))
+	copy(subErrors, e.subErrors)
+	return subErrors
+}
+
+// Is reports whether target has the same type and error as e, target is Template which created e,
+// or any sub error matches target by errors.Is()
 func (e *StructuredError) Is(target error) bool {
 	if target == nil {
 		return false
 	}
+	if tmpl, ok := target.(*Template); ok && e.template != nil && e.template == tmpl {
+		return true
+	}
 	targetFe, ok := target.(SError)
-	if !ok {
+	if ok && e.Type() == targetFe.Type() && errors.Is(e.Unwrap(), targetFe.Unwrap()) {
+		return true
+	}
+	for _, subErr := range e.subErrors {
+		if subErr != nil && errors.Is(subErr, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// As finds the first error matching target in the main error chain, then in sub errors
+// sub errors of StructuredError in the main error chain are looked into from the innermost one.
+// The main error chain is walked here without calling As() of nested StructuredError,
+// as calling them makes errors.As() take exponential time on chains of StructuredError.
+func (e *StructuredError) As(target any) bool {
+	val := reflect.ValueOf(target)
+	if target == nil || val.Kind() != reflect.Ptr || val.IsNil() {
 		return false
 	}
-	return e.Type() == targetFe.Type() && errors.Is(e.Unwrap(), targetFe.Unwrap())
+	targetType := val.Type().Elem()
+
+	chain := []*StructuredError{e}
+	err := e.err
+	for err != nil {
+		if reflect.TypeOf(err).AssignableTo(targetType) {
+			val.Elem().Set(reflect.ValueOf(err))
+			return true
+		}
+		if fe, ok := err.(*StructuredError); ok {
+			chain = append(chain, fe)
+			err = fe.err
+			continue
+		}
+		if x, ok := err.(interface{ As(any) bool }); ok && x.As(target) {
+			return true
+		}
+		switch x := err.(type) {
+		case interface{ Unwrap() error }:
+			err = x.Unwrap()
+		case interface{ Unwrap() []error }:
+			for _, wrapped := range x.Unwrap() {
+				if wrapped != nil && errors.As(wrapped, target) {
+					return true
+				}
+			}
+			err = nil
+		default:
+			err = nil
+		}
+	}
+
+	for i := len(chain) - 1; i >= 0; i-- {
+		for _, subErr := range chain[i].subErrors {
+			if subErr != nil && errors.As(subErr, target) {
+				return true
+			}
+		}
+	}
+	return false
 }
 
 func (e StructuredError) Type() ErrorType {
 	return e.errorType
 }
 
+// StackTrace returns the captured stack trace
+// frames are resolved at the first call, not when the stack trace is captured
 func (e StructuredError) StackTrace() StackTrace {
+	if e.callers != nil {
+		return e.callers.StackTrace()
+	}
 	if e.stacktrace == nil {
 		return make([]StackTraceItem, 0)
 	}
 	return e.stacktrace
 }
 
+// HasStackTrace reports whether the stack trace is set without resolving frames
+func (e StructuredError) HasStackTrace() bool {
+	if e.callers != nil {
+		return e.callers.len() > 0
+	}
+	return len(e.stacktrace) > 0
+}
+
 func (e StructuredError) When() *time.Time {
 	return e.when
 }
@@ -105,6 +210,27 @@ func (e StructuredError) RequestID() string {
 	return e.requestId
 }
 
+// Tags returns a copy of tags
+func (e StructuredError) Tags() Tags {
+	copied := NewTags()
+	for _, tag := range e.tags.tags {
+		copied.SetValueSafe(tag.Key, tag.Value)
+	}
+	return copied
+}
+
+// PublicMessage returns the message set by SetPublicMessage()
+// use PublicMessage(err) to get it with the default of the error type.
+func (e StructuredError) PublicMessage() string {
+	return e.publicMessage
+}
+
+// Origin returns the name of the service the error is decoded from by DecodeWire()
+// it is empty if the error is not remote or the service name is not known.
+func (e StructuredError) Origin() string {
+	return e.origin
+}
+
 func (e *StructuredError) SetErr(err error) SError {
 	e.err = err
 	return e
@@ -125,13 +251,21 @@ func (e *StructuredError) SetRequestID(requestID string) SError {
 	return e
 }
 
+// SetPublicMessage sets the message safe to show to users
+// it is printed in logs as public_message, and Error() keeps the internal message.
+func (e *StructuredError) SetPublicMessage(message string) SError {
+	e.publicMessage = message
+	return e
+}
+
 // WithStackTrace sets stack trace starting from caller of WithStackTrace
 func (e *StructuredError) WithStackTrace() SError {
 	return e.SetStackTraceWithSkipMaxDepth(2, MaxStackTraceDepth) // skip 2 to start at caller of WithStackTrace
 }
 
 func (e *StructuredError) SetStackTraceWithSkipMaxDepth(skip int, maxDepth int) SError {
-	e.stacktrace = NewStackTrace(skip, maxDepth)
+	e.callers = newCallers(skip, maxDepth)
+	e.stacktrace = nil
 	return e
 }
 
@@ -151,17 +285,33 @@ func (e *StructuredError) AddTagFloat(key string, value float64) SError {
 	return e.AddTagSafe(key, FloatTagValue(value))
 }
 
+func (e *StructuredError) AddTagTime(key string, value time.Time) SError {
+	return e.AddTagSafe(key, TimeTagValue{Time: value})
+}
+
+func (e *StructuredError) AddTagDuration(key string, value time.Duration) SError {
+	return e.AddTagSafe(key, DurationTagValue(value))
+}
+
+// AddTagSensitive adds value as SecretTagValue, which is printed as RedactedStr
+func (e *StructuredError) AddTagSensitive(key string, value any) SError {
+	return e.AddTagSafe(key, SecretTagValue{Value: ToTagValue(value)})
+}
+
 func (e *StructuredError) AddTagSafe(key string, value TagValue) SError {
 	e.tags.SetValueSafe(key, value)
 	return e
 }
 
-// It`s planned to be implemented later
-//func (e *StructuredError) AddTag(key string, value any) bool {
-//
-//	e.tags.SetValueSafe(key, InterfaceTagValue(value))
-//	return true
-//}
+// AddTag converts value into TagValue by ToTagValue() and adds it
+func (e *StructuredError) AddTag(key string, value any) SError {
+	return e.AddTagSafe(key, ToTagValue(value))
+}
+
+func (e StructuredError) hasTag(key string) bool {
+	_, ok := e.tags.GetIndexByKey(key)
+	return ok
+}
 
 func (e *StructuredError) DeleteTag(key string) SError {
 	e.tags.Delete(key)
@@ -189,7 +339,17 @@ func (e *StructuredError) AddSubError(errs ...error) SError {
 }
 
 func (e *StructuredError) JsonString() string {
-	return e.JsonPrinter().Print()
+	return e.errorJsonPrinter().Print()
+}
+
+// WriteJSON writes the same JSON as JsonString() to w
+func (e *StructuredError) WriteJSON(w io.Writer) error {
+	return e.errorJsonPrinter().WriteJSON(w)
+}
+
+// AppendJSON appends the same JSON as JsonString() to dst
+func (e *StructuredError) AppendJSON(dst []byte) []byte {
+	return e.errorJsonPrinter().AppendJSON(dst)
 }
 
 func (e *StructuredError) Format(f fmt.State, verb rune) {
@@ -208,27 +368,35 @@ func (e *StructuredError) Format(f fmt.State, verb rune) {
 }
 
 func (e *StructuredError) JsonPrinter() JsonPrinter {
+	return e.errorJsonPrinter()
+}
+
+func (e *StructuredError) errorJsonPrinter() ErrorJsonPrinter {
 	return ErrorJsonPrinter{
-		errorType:  e.errorType,
-		err:        e.err,
-		stacktrace: e.stacktrace,
-		when:       e.when,
-		requestId:  e.requestId,
-		tags:       e.tags,
-		subErrors:  e.subErrors,
+		errorType:     e.errorType,
+		err:           e.err,
+		stacktrace:    e.StackTrace(),
+		when:          e.when,
+		requestId:     e.requestId,
+		tags:          e.tags,
+		subErrors:     e.subErrors,
+		publicMessage: e.publicMessage,
+		origin:        e.origin,
 	}
 }
 
 func (e *StructuredError) VerbosePrinter() VerbosePrinter {
 	return ErrorVerbosePrinter{
-		title:      "main_error",
-		errorType:  e.errorType,
-		err:        e.err,
-		stacktrace: e.stacktrace,
-		when:       e.when,
-		requestId:  e.requestId,
-		tags:       e.tags,
-		subErrors:  e.subErrors,
+		title:         "main_error",
+		errorType:     e.errorType,
+		err:           e.err,
+		stacktrace:    e.StackTrace(),
+		when:          e.when,
+		requestId:     e.requestId,
+		tags:          e.tags,
+		subErrors:     e.subErrors,
+		publicMessage: e.publicMessage,
+		origin:        e.origin,
 	}
 }
 
@@ -255,12 +423,22 @@ type SError interface {
 	AddSubError(errs ...error) SError
 }
 
+// IsType() looks into sub errors through this interface
+type HasSubErrors interface {
+	SubErrors() []error
+}
+
 // IsType() use this interface
 // if your custom error implements it, It`s comparable in IsType()
 type HasType interface {
 	Type() ErrorType
 }
 
+// PublicMessage() use this interface
+type HasPublicMessage interface {
+	PublicMessage() string
+}
+
 type JsonStringer interface {
 	JsonString() string
 }
@@ -2,7 +2,9 @@ package serrors
 
 import (
 	"errors"
+	"fmt"
 	"testing"
+	"time"
 )
 
 func TestStructuredError_As(t *testing.T) {
@@ -33,6 +35,11 @@ func TestStructuredError_As(t *testing.T) {
 	10. CB has CA as FE -> false
 	11. CB has FE as FE -> true
 
+	12. FE has CA as sub error as CA -> true
+	13. FE has CB in main and CB in sub errors as CB -> main error CB
+	14. FE has NE as sub error as CA -> false
+	15. FE has FE with CA in sub errors wrapping CA in main as CA -> main error CA
+
 	*/
 
 	testCases := []struct {
@@ -230,6 +237,86 @@ func TestStructuredError_As(t *testing.T) {
 				}
 			},
 		},
+		{
+			label: "12. FE has CA as sub error as CA -> true",
+			scenarioFunc: func(t *testing.T) {
+				// prepare
+				fe := &StructuredError{err: ne, subErrors: []error{ne, ca}}
+				var target *testCustomNonStructuredError
+				expectedOk := true
+
+				// execute
+				ok := errors.As(fe, &target)
+
+				// verify
+				if ok != expectedOk {
+					t.Errorf("expected As to return %v, got %v", expectedOk, ok)
+				}
+				if target != ca {
+					t.Errorf("expected target %v, got %v", ca, target)
+				}
+			},
+		},
+		{
+			label: "13. FE has CB in main and CB in sub errors as CB -> main error CB",
+			scenarioFunc: func(t *testing.T) {
+				// prepare
+				sub := newTestCustomStructuredError1(400)
+				fe := &StructuredError{err: cb, subErrors: []error{sub}}
+				var target *testCustomStructuredError1
+				expectedOk := true
+
+				// execute
+				ok := errors.As(fe, &target)
+
+				// verify
+				if ok != expectedOk {
+					t.Errorf("expected As to return %v, got %v", expectedOk, ok)
+				}
+				if target != cb {
+					t.Errorf("expected target %v, got %v", cb, target)
+				}
+			},
+		},
+		{
+			label: "14. FE has NE as sub error as CA -> false",
+			scenarioFunc: func(t *testing.T) {
+				// prepare
+				fe := &StructuredError{err: ne, subErrors: []error{ne}}
+				var target *testCustomNonStructuredError
+				expectedOk := false
+
+				// execute
+				ok := errors.As(fe, &target)
+
+				// verify
+				if ok != expectedOk {
+					t.Errorf("expected As to return %v, got %v", expectedOk, ok)
+				}
+			},
+		},
+		{
+			label: "15. FE has FE with CA in sub errors wrapping CA in main as CA -> main error CA",
+			scenarioFunc: func(t *testing.T) {
+				// prepare
+				sub := newTestCustomNonStructuredError("sub", 400)
+				nested := &StructuredError{err: fmt.Errorf("wrap: %w", ca), subErrors: []error{sub}}
+				fe := &StructuredError{err: nested, subErrors: []error{sub}}
+				var target *testCustomNonStructuredError
+				expectedOk := true
+
+				// execute
+				ok := errors.As(fe, &target)
+
+				// verify
+				if ok != expectedOk {
+					t.Errorf("expected As to return %v, got %v", expectedOk, ok)
+				}
+				if target != ca {
+					t.Errorf("expected target %v, got %v", ca, target)
+				}
+			},
+		},
 	}
 
 	for _, tc := range testCases {
@@ -238,3 +325,27 @@ func TestStructuredError_As(t *testing.T) {
 		})
 	}
 }
+
+func TestStructuredError_As_DeepChain(t *testing.T) {
+	var err error = errors.New("root")
+	for i := 0; i < 64; i++ {
+		err = Errorf("level %d: %w", i, err)
+		_ = err.(SError).AddSubError(errors.New("sub"))
+	}
+	_ = err.(SError).AddSubError(newTestCustomNonStructuredError("sub", 1))
+
+	done := make(chan bool)
+	go func() {
+		var miss *testCustomStructuredError1
+		var hit *testCustomNonStructuredError
+		done <- !errors.As(err, &miss) && errors.As(err, &hit)
+	}()
+	select {
+	case ok := <-done:
+		if !ok {
+			t.Errorf("unexpected result of errors.As()")
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("errors.As() takes too long on a deep chain")
+	}
+}
@@ -68,6 +68,10 @@ func TestStructuredError_Is(t *testing.T) {
 		8. FE has nil target is nil -> false
 
 		9. FE has NE is NE -> true
+
+		10. FE has NE as sub error is NE -> true
+		11. FE has CA as sub error of sub error is CA -> true
+		12. FE has sub errors is dif NE -> false
 		*/
 		{
 			label:    "1. FE has NE is FE has same NE -> true",
@@ -123,6 +127,27 @@ func TestStructuredError_Is(t *testing.T) {
 			target:   ne,
 			expected: true,
 		},
+		{
+			label:    "10. FE has NE as sub error is NE -> true",
+			err:      &StructuredError{err: errors.New("main"), subErrors: []error{ne}},
+			target:   ne,
+			expected: true,
+		},
+		{
+			label: "11. FE has CA as sub error of sub error is CA -> true",
+			err: &StructuredError{
+				err:       errors.New("main"),
+				subErrors: []error{nil, &StructuredError{subErrors: []error{fmt.Errorf("wrap: %w", ca)}}},
+			},
+			target:   ca,
+			expected: true,
+		},
+		{
+			label:    "12. FE has sub errors is dif NE -> false",
+			err:      &StructuredError{err: errors.New("main"), subErrors: []error{errors.New("sub")}},
+			target:   errors.New("sub"),
+			expected: false,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -191,6 +216,33 @@ func TestIsType(t *testing.T) {
 			target:   testErrorType1,
 			expected: true,
 		},
+		{
+			label: "sub error with type testCustom1 is testCustom1 -> true",
+			err: &StructuredError{
+				err:       errors.New("main"),
+				subErrors: []error{errors.New("sub"), fmt.Errorf("wrap: %w", newTestCustomStructuredError1(100))},
+			},
+			target:   testErrorType1,
+			expected: true,
+		},
+		{
+			label: "sub error of wrapped StructuredError is testCustom1 -> true",
+			err: fmt.Errorf("wrap: %w", &StructuredError{
+				errorType: ErrorType("other"),
+				subErrors: []error{&StructuredError{errorType: testErrorType1}},
+			}),
+			target:   testErrorType1,
+			expected: true,
+		},
+		{
+			label: "sub errors without type testCustom1 is testCustom1 -> false",
+			err: &StructuredError{
+				err:       errors.New("main"),
+				subErrors: []error{errors.New("sub")},
+			},
+			target:   testErrorType1,
+			expected: false,
+		},
 	}
 
 	for _, tc := range testCases {
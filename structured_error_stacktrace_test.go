@@ -6,15 +6,16 @@ func TestStructuredError_WithStackTrace(t *testing.T) {
 	// WithStackTrace should start capturing from the caller of WithStackTrace
 	err := &StructuredError{}
 	_ = err.WithStackTrace() // 8
-	if len(err.stacktrace) == 0 {
+	stacktrace := err.StackTrace()
+	if len(stacktrace) == 0 {
 		t.Errorf("expected stacktrace to be set, but it was empty")
 	}
-	firstFrame := err.stacktrace[0]
+	firstFrame := stacktrace[0]
 	if firstFrame.Function != "github.com/hinoguma/go-structured-error.TestStructuredError_WithStackTrace" {
-		t.Errorf("expected top stack frame to be TestStructuredError_WithStackTrace, but got %s", err.stacktrace[0].Function)
+		t.Errorf("expected top stack frame to be TestStructuredError_WithStackTrace, but got %s", stacktrace[0].Function)
 	}
 	if firstFrame.Line != 8 {
-		t.Errorf("expected top stack frame line to be 9, but got %d", err.stacktrace[0].Line)
+		t.Errorf("expected top stack frame line to be 9, but got %d", stacktrace[0].Line)
 	}
 }
 
@@ -87,8 +88,80 @@ func TestStructuredError_SetStackTraceWithSkipMaxDepth(t *testing.T) {
 		t.Run(tc.label, func(t *testing.T) {
 			err := &StructuredError{}
 			setStackTraceWithSkipMaxDepth1(err, tc.skip, tc.depth)
-			assertEqualsStackTrace(t, err.stacktrace, tc.expected, "github.com/hinoguma/go-structured-error.")
+			assertEqualsStackTrace(t, err.StackTrace(), tc.expected, "github.com/hinoguma/go-structured-error.")
 		})
 	}
 
 }
+
+func TestStructuredError_HasStackTrace(t *testing.T) {
+	testCases := []struct {
+		label    string
+		err      *StructuredError
+		expected bool
+	}{
+		{
+			label:    "empty",
+			err:      &StructuredError{},
+			expected: false,
+		},
+		{
+			label: "resolved stack trace",
+			err: &StructuredError{
+				stacktrace: StackTrace{{File: "example.go", Line: 10, Function: "main.exampleFunction"}},
+			},
+			expected: true,
+		},
+		{
+			label: "captured callers",
+			err: func() *StructuredError {
+				err := &StructuredError{}
+				_ = err.WithStackTrace()
+				return err
+			}(),
+			expected: true,
+		},
+		{
+			label: "captured with zero depth",
+			err: func() *StructuredError {
+				err := &StructuredError{}
+				_ = err.SetStackTraceWithSkipMaxDepth(0, 0)
+				return err
+			}(),
+			expected: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.label, func(t *testing.T) {
+			if got := tc.err.HasStackTrace(); got != tc.expected {
+				t.Errorf("expected %v, got %v", tc.expected, got)
+			}
+			if got := len(tc.err.StackTrace()) > 0; got != tc.expected {
+				t.Errorf("expected stack trace existence %v, got %v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestStructuredError_StackTraceResolvedOnce(t *testing.T) {
+	err := &StructuredError{}
+	_ = err.WithStackTrace()
+	if err.callers == nil || len(err.callers.frames) != 0 {
+		t.Fatalf("expected frames not to be resolved before StackTrace() is called")
+	}
+
+	done := make(chan StackTrace)
+	for i := 0; i < 4; i++ {
+		go func() {
+			done <- err.StackTrace()
+		}()
+	}
+	first := <-done
+	for i := 1; i < 4; i++ {
+		got := <-done
+		if &got[0] != &first[0] {
+			t.Errorf("expected resolved frames to be shared")
+		}
+	}
+}
@@ -195,6 +195,10 @@ func TestStructuredError_AddTag(t *testing.T) {
 				_ = err.AddTagBool("tag3", true)
 				_ = err.AddTagFloat("tag4", 3.14)
 				_ = err.AddTagSafe("tag5", StringTagValue("safeValue"))
+				_ = err.AddTag("tag6", uint8(8))
+				_ = err.AddTag("tag7", []string{"a"})
+				_ = err.AddTagTime("tag8", time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+				_ = err.AddTagDuration("tag9", time.Second)
 			},
 			expected: &StructuredError{
 				tags: Tags{
@@ -204,6 +208,10 @@ func TestStructuredError_AddTag(t *testing.T) {
 						{Key: "tag3", Value: BoolTagValue(true)},
 						{Key: "tag4", Value: FloatTagValue(3.14)},
 						{Key: "tag5", Value: StringTagValue("safeValue")},
+						{Key: "tag6", Value: IntTagValue(8)},
+						{Key: "tag7", Value: ArrayTagValue{StringTagValue("a")}},
+						{Key: "tag8", Value: TimeTagValue{Time: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)}},
+						{Key: "tag9", Value: DurationTagValue(time.Second)},
 					},
 					keyMap: map[string]int{
 						"tag1": 0,
@@ -211,6 +219,10 @@ func TestStructuredError_AddTag(t *testing.T) {
 						"tag3": 2,
 						"tag4": 3,
 						"tag5": 4,
+						"tag6": 5,
+						"tag7": 6,
+						"tag8": 7,
+						"tag9": 8,
 					},
 				},
 			},
@@ -596,3 +608,21 @@ func TestStructuredError_Format(t *testing.T) {
 		})
 	}
 }
+
+func TestStructuredError_SubErrors(t *testing.T) {
+	sub1 := errors.New("sub error 1")
+	sub2 := errors.New("sub error 2")
+	fe := NewRawStructuredError(errors.New("main"))
+	_ = fe.AddSubError(sub1, nil, sub2)
+
+	got := fe.SubErrors()
+	if !reflect.DeepEqual(got, []error{sub1, sub2}) {
+		t.Errorf("expected sub errors %v, got %v", []error{sub1, sub2}, got)
+	}
+
+	// returned slice is a copy
+	got[0] = nil
+	if fe.subErrors[0] != sub1 {
+		t.Errorf("expected sub errors not to be modified, got %v", fe.subErrors)
+	}
+}
@@ -1,8 +1,13 @@
 package serrors
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"time"
 )
 
 // TagValue.String is a methof to convert the tag value to a string for verbose output
@@ -19,10 +24,13 @@ func (v StringTagValue) String() string {
 }
 
 func (v StringTagValue) JsonValueString() string {
+	return string(v.AppendJSON(nil))
+}
+
+func (v StringTagValue) AppendJSON(dst []byte) []byte {
 	// escape the string for JSON
 	// line breaks and special characters will be escaped
-	s, _ := json.Marshal(v.String())
-	return string(s)
+	return appendJsonString(dst, string(v))
 }
 
 type IntTagValue int
@@ -35,6 +43,10 @@ func (v IntTagValue) JsonValueString() string {
 	return v.String()
 }
 
+func (v IntTagValue) AppendJSON(dst []byte) []byte {
+	return strconv.AppendInt(dst, int64(v), 10)
+}
+
 type BoolTagValue bool
 
 func (v BoolTagValue) String() string {
@@ -48,6 +60,10 @@ func (v BoolTagValue) JsonValueString() string {
 	return v.String()
 }
 
+func (v BoolTagValue) AppendJSON(dst []byte) []byte {
+	return strconv.AppendBool(dst, bool(v))
+}
+
 type FloatTagValue float64
 
 func (v FloatTagValue) String() string {
@@ -55,7 +71,22 @@ func (v FloatTagValue) String() string {
 }
 
 func (v FloatTagValue) JsonValueString() string {
-	return v.String()
+	return string(v.AppendJSON(nil))
+}
+
+// NaN and Inf are not valid JSON numbers, so they are written as strings
+// integral values are written with a fraction like 3.0, so ParseTagValue() restores them as FloatTagValue.
+func (v FloatTagValue) AppendJSON(dst []byte) []byte {
+	f := float64(v)
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return appendJsonString(dst, v.String())
+	}
+	start := len(dst)
+	dst = strconv.AppendFloat(dst, f, 'g', -1, 64)
+	if !bytes.ContainsAny(dst[start:], ".eE") {
+		dst = append(dst, ".0"...)
+	}
+	return dst
 }
 
 type NilTagValue struct{}
@@ -67,3 +98,300 @@ func (v NilTagValue) String() string {
 func (v NilTagValue) JsonValueString() string {
 	return v.String()
 }
+
+func (v NilTagValue) AppendJSON(dst []byte) []byte {
+	return append(dst, "null"...)
+}
+
+// TimeTagValue is a tag value of time.Time
+// Layout is used for both verbose and JSON output. if it is empty, time.RFC3339Nano is used.
+type TimeTagValue struct {
+	Time   time.Time
+	Layout string
+}
+
+func (v TimeTagValue) String() string {
+	return v.Time.Format(v.layout())
+}
+
+func (v TimeTagValue) JsonValueString() string {
+	return string(v.AppendJSON(nil))
+}
+
+func (v TimeTagValue) AppendJSON(dst []byte) []byte {
+	return appendJsonString(dst, v.String())
+}
+
+func (v TimeTagValue) layout() string {
+	if v.Layout == "" {
+		return time.RFC3339Nano
+	}
+	return v.Layout
+}
+
+// DurationTagValue is a tag value of time.Duration
+// In JSON, it has both human readable string and numbers to be searchable in log tools.
+// e.g. {"string":"1.5s","nanoseconds":1500000000,"milliseconds":1500}
+type DurationTagValue time.Duration
+
+func (v DurationTagValue) String() string {
+	return time.Duration(v).String()
+}
+
+func (v DurationTagValue) JsonValueString() string {
+	return string(v.AppendJSON(nil))
+}
+
+func (v DurationTagValue) AppendJSON(dst []byte) []byte {
+	dst = append(dst, `{"string":`...)
+	dst = appendJsonString(dst, v.String())
+	dst = append(dst, `,"nanoseconds":`...)
+	dst = strconv.AppendInt(dst, int64(v), 10)
+	dst = append(dst, `,"milliseconds":`...)
+	dst = strconv.AppendFloat(dst, float64(v)/float64(time.Millisecond), 'g', -1, 64)
+	return append(dst, '}')
+}
+
+// ObjectTagValue is a tag value of nested key-value pairs
+// The order of keys is preserved as Tags.
+//
+//	user := serrors.NewTags()
+//	user.SetValue("id", 1)
+//	user.SetValue("plan", "pro")
+//	err.AddTagSafe("user", serrors.ObjectTagValue(user))
+type ObjectTagValue Tags
+
+func (v ObjectTagValue) String() string {
+	return v.JsonValueString()
+}
+
+func (v ObjectTagValue) JsonValueString() string {
+	return string(v.AppendJSON(nil))
+}
+
+func (v ObjectTagValue) AppendJSON(dst []byte) []byte {
+	return v.appendJSONDepth(dst, 1)
+}
+
+func (v ObjectTagValue) appendJSONDepth(dst []byte, depth int) []byte {
+	if depth > MaxTagValueDepth {
+		return appendJsonString(dst, TruncatedTagValueStr)
+	}
+	dst = append(dst, '{')
+	for i, tag := range v.tags {
+		if i > 0 {
+			dst = append(dst, JsonItemSeparator...)
+		}
+		dst = appendJsonString(dst, tag.Key)
+		dst = append(dst, ':')
+		dst = appendJsonValueDepth(dst, tag.Value, depth+1)
+	}
+	return append(dst, '}')
+}
+
+func (v ObjectTagValue) verboseLines(dst []string, indent string, depth int) []string {
+	for _, tag := range v.tags {
+		dst = appendVerboseTagLines(dst, indent, tag.Key+":", tag.Value, depth+1)
+	}
+	return dst
+}
+
+// ArrayTagValue is a tag value of a list
+type ArrayTagValue []TagValue
+
+func (v ArrayTagValue) String() string {
+	return v.JsonValueString()
+}
+
+func (v ArrayTagValue) JsonValueString() string {
+	return string(v.AppendJSON(nil))
+}
+
+func (v ArrayTagValue) AppendJSON(dst []byte) []byte {
+	return v.appendJSONDepth(dst, 1)
+}
+
+func (v ArrayTagValue) appendJSONDepth(dst []byte, depth int) []byte {
+	if depth > MaxTagValueDepth {
+		return appendJsonString(dst, TruncatedTagValueStr)
+	}
+	dst = append(dst, '[')
+	for i, item := range v {
+		if i > 0 {
+			dst = append(dst, JsonItemSeparator...)
+		}
+		dst = appendJsonValueDepth(dst, item, depth+1)
+	}
+	return append(dst, ']')
+}
+
+// MarshalText makes text handlers of log/slog print the JSON
+func (v ArrayTagValue) MarshalText() ([]byte, error) {
+	return []byte(v.JsonValueString()), nil
+}
+
+func (v ArrayTagValue) verboseLines(dst []string, indent string, depth int) []string {
+	for _, item := range v {
+		dst = appendVerboseTagLines(dst, indent, "-", item, depth+1)
+	}
+	return dst
+}
+
+// SecretTagValue is a tag value which must not be leaked into logs
+// It is always printed as RedactedStr unless a Redactor replaces it, e.g. HashRedactor.
+// The original value is kept in Value for debugging in code.
+type SecretTagValue struct {
+	Value TagValue
+}
+
+func (v SecretTagValue) String() string {
+	return RedactedStr
+}
+
+func (v SecretTagValue) JsonValueString() string {
+	return string(v.AppendJSON(nil))
+}
+
+func (v SecretTagValue) AppendJSON(dst []byte) []byte {
+	return appendJsonString(dst, RedactedStr)
+}
+
+// JsonTagValue is a tag value holding raw JSON such as objects and arrays
+// If it is not valid JSON, it is written as a JSON string.
+type JsonTagValue []byte
+
+func (v JsonTagValue) String() string {
+	return string(v)
+}
+
+func (v JsonTagValue) JsonValueString() string {
+	return string(v.AppendJSON(nil))
+}
+
+func (v JsonTagValue) AppendJSON(dst []byte) []byte {
+	if len(v) == 0 {
+		return append(dst, "null"...)
+	}
+	if !json.Valid(v) {
+		return appendJsonString(dst, string(v))
+	}
+	return append(dst, v...)
+}
+
+// MarshalText makes text handlers of log/slog print the raw JSON
+func (v JsonTagValue) MarshalText() ([]byte, error) {
+	return []byte(v.String()), nil
+}
+
+// ToTagValue converts any Go value into TagValue
+//
+//   - nil and nil pointers, maps, slices and interfaces become NilTagValue
+//   - TagValue is returned as is
+//   - all int and uint widths become IntTagValue, or StringTagValue if the value overflows int
+//   - floats become FloatTagValue, bool becomes BoolTagValue, string and []byte become StringTagValue
+//   - time.Time becomes TimeTagValue and time.Duration becomes DurationTagValue
+//   - error and fmt.Stringer become StringTagValue
+//   - slices, arrays, maps and structs become ArrayTagValue or ObjectTagValue through json.Marshal,
+//     so json struct tags and json.Marshaler are respected, and map keys are sorted
+//   - JsonTagValue is only made from values which already are JsonTagValue
+//   - anything else becomes StringTagValue formatted by fmt
+func ToTagValue(value any) TagValue {
+	switch v := value.(type) {
+	case nil:
+		return NilTagValue{}
+	case TagValue:
+		return v
+	case string:
+		return StringTagValue(v)
+	case bool:
+		return BoolTagValue(v)
+	case int:
+		return IntTagValue(v)
+	case float64:
+		return FloatTagValue(v)
+	case []byte:
+		if v == nil {
+			return NilTagValue{}
+		}
+		return StringTagValue(v)
+	case time.Time:
+		return TimeTagValue{Time: v}
+	case time.Duration:
+		return DurationTagValue(v)
+	case error:
+		if isNilValue(v) {
+			return NilTagValue{}
+		}
+		return StringTagValue(v.Error())
+	case fmt.Stringer:
+		if isNilValue(v) {
+			return NilTagValue{}
+		}
+		return StringTagValue(v.String())
+	}
+	return reflectTagValue(reflect.ValueOf(value))
+}
+
+func reflectTagValue(rv reflect.Value) TagValue {
+	switch rv.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if rv.IsNil() {
+			return NilTagValue{}
+		}
+		return ToTagValue(rv.Elem().Interface())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i := rv.Int()
+		if i < math.MinInt || i > math.MaxInt {
+			return StringTagValue(strconv.FormatInt(i, 10))
+		}
+		return IntTagValue(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		u := rv.Uint()
+		if u > math.MaxInt {
+			return StringTagValue(strconv.FormatUint(u, 10))
+		}
+		return IntTagValue(u)
+	case reflect.Float32:
+		// format with 32 bit precision to avoid 0.1 becoming 0.10000000149011612
+		f, _ := strconv.ParseFloat(strconv.FormatFloat(rv.Float(), 'g', -1, 32), 64)
+		return FloatTagValue(f)
+	case reflect.Float64:
+		return FloatTagValue(rv.Float())
+	case reflect.Bool:
+		return BoolTagValue(rv.Bool())
+	case reflect.String:
+		return StringTagValue(rv.String())
+	case reflect.Slice, reflect.Map:
+		if rv.IsNil() {
+			return NilTagValue{}
+		}
+		return jsonTagValue(rv.Interface())
+	case reflect.Array, reflect.Struct:
+		return jsonTagValue(rv.Interface())
+	}
+	return StringTagValue(fmt.Sprintf("%v", rv.Interface()))
+}
+
+// jsonTagValue converts value into nested tag values through its JSON
+// so tags inside can be redacted and are restored by ParseTagValue() as the same types.
+func jsonTagValue(value any) TagValue {
+	b, err := json.Marshal(value)
+	if err != nil {
+		return StringTagValue(fmt.Sprintf("%v", value))
+	}
+	tv, err := ParseTagValue(b)
+	if err != nil {
+		return StringTagValue(string(b))
+	}
+	return tv
+}
+
+// isNilValue reports whether v is an interface holding a nil pointer
+func isNilValue(v any) bool {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Slice, reflect.Interface, reflect.Func, reflect.Chan:
+		return rv.IsNil()
+	}
+	return false
+}
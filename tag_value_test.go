@@ -1,6 +1,12 @@
 package serrors
 
-import "testing"
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
 
 func TestTagValue_String(t *testing.T) {
 	testCases := []struct {
@@ -38,6 +44,21 @@ func TestTagValue_String(t *testing.T) {
 			tagVal:   NilTagValue{},
 			expected: "null",
 		},
+		{
+			label:    "TimeTagValue returns RFC3339Nano by default",
+			tagVal:   TimeTagValue{Time: time.Date(2024, 1, 1, 12, 0, 0, 1000, time.UTC)},
+			expected: "2024-01-01T12:00:00.000001Z",
+		},
+		{
+			label:    "TimeTagValue returns string in layout",
+			tagVal:   TimeTagValue{Time: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC), Layout: time.DateOnly},
+			expected: "2024-01-01",
+		},
+		{
+			label:    "DurationTagValue returns human readable string",
+			tagVal:   DurationTagValue(1500 * time.Millisecond),
+			expected: "1.5s",
+		},
 	}
 
 	for _, tc := range testCases {
@@ -86,11 +107,216 @@ func TestTagValue_JsonValueString(t *testing.T) {
 			tagVal:   FloatTagValue(3.14),
 			expected: "3.14",
 		},
+		{
+			label:    "FloatTagValue integral",
+			tagVal:   FloatTagValue(3),
+			expected: "3.0",
+		},
+		{
+			label:    "FloatTagValue integral exponent",
+			tagVal:   FloatTagValue(1e21),
+			expected: "1e+21",
+		},
+		{
+			label:    "FloatTagValue NaN",
+			tagVal:   FloatTagValue(math.NaN()),
+			expected: `"NaN"`,
+		},
+		{
+			label:    "FloatTagValue Inf",
+			tagVal:   FloatTagValue(math.Inf(-1)),
+			expected: `"-Inf"`,
+		},
 		{
 			label:    "NilTagValue",
 			tagVal:   NilTagValue{},
 			expected: "null",
 		},
+		{
+			label:    "TimeTagValue",
+			tagVal:   TimeTagValue{Time: time.Date(2024, 1, 1, 12, 0, 0, 0, time.FixedZone("JST", 9*60*60))},
+			expected: `"2024-01-01T12:00:00+09:00"`,
+		},
+		{
+			label:    "TimeTagValue with layout",
+			tagVal:   TimeTagValue{Time: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC), Layout: time.RFC1123},
+			expected: `"Mon, 01 Jan 2024 12:00:00 UTC"`,
+		},
+		{
+			label:    "DurationTagValue",
+			tagVal:   DurationTagValue(1500 * time.Millisecond),
+			expected: `{"string":"1.5s","nanoseconds":1500000000,"milliseconds":1500}`,
+		},
+		{
+			label:    "DurationTagValue less than millisecond",
+			tagVal:   DurationTagValue(1500 * time.Microsecond),
+			expected: `{"string":"1.5ms","nanoseconds":1500000,"milliseconds":1.5}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.label, func(t *testing.T) {
+			got := tc.tagVal.JsonValueString()
+			if got != tc.expected {
+				t.Errorf("expected JSON string %v, got %v", tc.expected, got)
+			}
+		})
+	}
+}
+
+type testStringer struct{}
+
+func (testStringer) String() string {
+	return "stringer"
+}
+
+type testTagStruct struct {
+	ID   int    `json:"id"`
+	Plan string `json:"plan"`
+}
+
+type testNamedInt int
+
+func TestToTagValue(t *testing.T) {
+	var nilPointer *testTagStruct
+	var nilError *testCustomError2
+	var nilMap map[string]int
+	num := 7
+
+	testCases := []struct {
+		label    string
+		value    any
+		expected TagValue
+	}{
+		{label: "nil", value: nil, expected: NilTagValue{}},
+		{label: "nil pointer", value: nilPointer, expected: NilTagValue{}},
+		{label: "nil error pointer", value: nilError, expected: NilTagValue{}},
+		{label: "nil map", value: nilMap, expected: NilTagValue{}},
+		{label: "TagValue", value: FloatTagValue(1.5), expected: FloatTagValue(1.5)},
+		{label: "string", value: "text", expected: StringTagValue("text")},
+		{label: "bool", value: true, expected: BoolTagValue(true)},
+		{label: "int", value: 42, expected: IntTagValue(42)},
+		{label: "int8", value: int8(-8), expected: IntTagValue(-8)},
+		{label: "int16", value: int16(16), expected: IntTagValue(16)},
+		{label: "int32", value: int32(32), expected: IntTagValue(32)},
+		{label: "int64", value: int64(64), expected: IntTagValue(64)},
+		{label: "uint", value: uint(1), expected: IntTagValue(1)},
+		{label: "uint8", value: uint8(8), expected: IntTagValue(8)},
+		{label: "uint16", value: uint16(16), expected: IntTagValue(16)},
+		{label: "uint32", value: uint32(32), expected: IntTagValue(32)},
+		{label: "uint64 overflowing int", value: uint64(math.MaxUint64), expected: StringTagValue("18446744073709551615")},
+		{label: "named int", value: testNamedInt(3), expected: IntTagValue(3)},
+		{label: "float32", value: float32(0.1), expected: FloatTagValue(0.1)},
+		{label: "float64", value: 3.14, expected: FloatTagValue(3.14)},
+		{label: "pointer", value: &num, expected: IntTagValue(7)},
+		{label: "bytes", value: []byte("raw"), expected: StringTagValue("raw")},
+		{label: "time", value: time.Date(2024, 1, 1, 12, 0, 0, 500, time.UTC), expected: TimeTagValue{Time: time.Date(2024, 1, 1, 12, 0, 0, 500, time.UTC)}},
+		{label: "duration", value: 1500 * time.Millisecond, expected: DurationTagValue(1500 * time.Millisecond)},
+		{label: "error", value: errors.New("some error"), expected: StringTagValue("some error")},
+		{label: "stringer", value: testStringer{}, expected: StringTagValue("stringer")},
+		{label: "slice", value: []int{1, 2}, expected: ArrayTagValue{IntTagValue(1), IntTagValue(2)}},
+		{label: "array", value: [2]string{"a", "b"}, expected: ArrayTagValue{StringTagValue("a"), StringTagValue("b")}},
+		{label: "map", value: map[string]int{"b": 2, "a": 1}, expected: newTestObjectTagValue("a", IntTagValue(1), "b", IntTagValue(2))},
+		{label: "struct", value: testTagStruct{ID: 1, Plan: "pro"}, expected: newTestObjectTagValue("id", IntTagValue(1), "plan", StringTagValue("pro"))},
+		{label: "struct pointer", value: &testTagStruct{ID: 2}, expected: newTestObjectTagValue("id", IntTagValue(2), "plan", StringTagValue(""))},
+		{label: "nested", value: map[string]any{"ids": []int{1}, "user": nil}, expected: newTestObjectTagValue("ids", ArrayTagValue{IntTagValue(1)}, "user", NilTagValue{})},
+		{label: "raw json", value: JsonTagValue(`{"a":1}`), expected: JsonTagValue(`{"a":1}`)},
+		{label: "not json marshalable", value: []func(){nil}, expected: StringTagValue("[<nil>]")},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.label, func(t *testing.T) {
+			got := ToTagValue(tc.value)
+			if !reflect.DeepEqual(got, tc.expected) {
+				t.Errorf("expected %#v, got %#v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestJsonTagValue_JsonValueString(t *testing.T) {
+	testCases := []struct {
+		label    string
+		tagVal   JsonTagValue
+		expected string
+	}{
+		{label: "object", tagVal: JsonTagValue(`{"a":[1,2]}`), expected: `{"a":[1,2]}`},
+		{label: "empty", tagVal: JsonTagValue(nil), expected: `null`},
+		{label: "invalid json", tagVal: JsonTagValue(`{"a":`), expected: `"{\"a\":"`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.label, func(t *testing.T) {
+			got := tc.tagVal.JsonValueString()
+			if got != tc.expected {
+				t.Errorf("expected JSON string %v, got %v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func newTestObjectTagValue(kv ...any) ObjectTagValue {
+	tags := NewTags()
+	for i := 0; i+1 < len(kv); i += 2 {
+		tags.SetValueSafe(kv[i].(string), kv[i+1].(TagValue))
+	}
+	return ObjectTagValue(tags)
+}
+
+func newTestNestedArray(depth int) TagValue {
+	var v TagValue = IntTagValue(1)
+	for i := 0; i < depth; i++ {
+		v = ArrayTagValue{v}
+	}
+	return v
+}
+
+func TestNestedTagValue_JsonValueString(t *testing.T) {
+	testCases := []struct {
+		label    string
+		tagVal   TagValue
+		expected string
+	}{
+		{
+			label: "object keeps order",
+			tagVal: newTestObjectTagValue(
+				"z", IntTagValue(1),
+				"a", StringTagValue("pro"),
+			),
+			expected: `{"z":1,"a":"pro"}`,
+		},
+		{
+			label:    "empty object",
+			tagVal:   ObjectTagValue(NewTags()),
+			expected: `{}`,
+		},
+		{
+			label:    "array",
+			tagVal:   ArrayTagValue{IntTagValue(1), StringTagValue("two"), NilTagValue{}},
+			expected: `[1,"two",null]`,
+		},
+		{
+			label:    "empty array",
+			tagVal:   ArrayTagValue{},
+			expected: `[]`,
+		},
+		{
+			label: "object in array in object",
+			tagVal: newTestObjectTagValue(
+				"users", ArrayTagValue{newTestObjectTagValue("id", IntTagValue(1), `"key"`, BoolTagValue(true))},
+			),
+			expected: `{"users":[{"id":1,"\"key\"":true}]}`,
+		},
+		{
+			label:    "max depth",
+			tagVal:   newTestNestedArray(MaxTagValueDepth),
+			expected: `[[[[[[[[1]]]]]]]]`,
+		},
+		{
+			label:    "beyond max depth",
+			tagVal:   newTestNestedArray(MaxTagValueDepth + 1),
+			expected: `[[[[[[[["[truncated]"]]]]]]]]`,
+		},
 	}
 
 	for _, tc := range testCases {
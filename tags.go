@@ -39,11 +39,30 @@ func (tags Tags) GetValue(key string) (TagValue, bool) {
 	return tags.tags[index].Value, true
 }
 
-// SetValue() is not implemented yet.
-// It`s easy and simple for user to add value to Tags
-//func (tags Tags) SetValue(key string, value any) bool {
-//	panic("not implemented")
-//}
+// List returns a copy of tags in order
+func (tags Tags) List() []Tag {
+	list := make([]Tag, len(tags.tags))
+	copy(list, tags.tags)
+	return list
+}
+
+// clone returns a copy of tags which can be changed independently
+func (tags Tags) clone() Tags {
+	cloned := Tags{
+		tags:   make([]Tag, len(tags.tags)),
+		keyMap: make(map[string]int, len(tags.keyMap)),
+	}
+	copy(cloned.tags, tags.tags)
+	for key, index := range tags.keyMap {
+		cloned.keyMap[key] = index
+	}
+	return cloned
+}
+
+// SetValue converts value into TagValue by ToTagValue() and sets it
+func (tags *Tags) SetValue(key string, value any) {
+	tags.SetValueSafe(key, ToTagValue(value))
+}
 
 // SetValueSafe sets the value of a tag with the given key.
 //
@@ -111,13 +130,19 @@ func (tags *Tags) deleteKey(key string) {
 }
 
 func (tags Tags) JsonValueString() string {
-	result := "{"
+	return string(tags.AppendJSON(nil))
+}
+
+// AppendJSON appends the same JSON as JsonValueString() to dst
+func (tags Tags) AppendJSON(dst []byte) []byte {
+	dst = append(dst, '{')
 	for i, tag := range tags.tags {
 		if i > 0 {
-			result += JsonItemSeparator
+			dst = append(dst, JsonItemSeparator...)
 		}
-		result += "\"" + tag.Key + "\":" + tag.Value.JsonValueString()
+		dst = appendJsonString(dst, tag.Key)
+		dst = append(dst, ':')
+		dst = appendJsonValue(dst, tag.Value)
 	}
-	result += "}"
-	return result
+	return append(dst, '}')
 }
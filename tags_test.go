@@ -695,6 +695,20 @@ func TestTags_JsonValueString(t *testing.T) {
 			},
 			expected: `{"key1":"value1","key2":42,"key3":true}`,
 		},
+		{
+			label: "keys with special characters",
+			tags: Tags{
+				tags: []Tag{
+					{Key: `quoted "key"`, Value: IntTagValue(1)},
+					{Key: "line\nbreak", Value: IntTagValue(2)},
+				},
+				keyMap: map[string]int{
+					`quoted "key"`: 0,
+					"line\nbreak":  1,
+				},
+			},
+			expected: `{"quoted \"key\"":1,"line\nbreak":2}`,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -734,3 +748,48 @@ func TestTag_String(t *testing.T) {
 		})
 	}
 }
+
+func TestTags_SetValue(t *testing.T) {
+	tags := NewTags()
+	tags.SetValue("key1", int32(1))
+	tags.SetValue("key2", "value2")
+	expected := Tags{
+		tags: []Tag{
+			{Key: "key1", Value: IntTagValue(1)},
+			{Key: "key2", Value: StringTagValue("value2")},
+		},
+		keyMap: map[string]int{
+			"key1": 0,
+			"key2": 1,
+		},
+	}
+	assertEqualsTags(t, tags, expected)
+}
+
+func TestTags_List(t *testing.T) {
+	tags := NewTags()
+	tags.SetValue("b", 1)
+	tags.SetValue("a", "x")
+	list := tags.List()
+	expected := []Tag{{Key: "b", Value: IntTagValue(1)}, {Key: "a", Value: StringTagValue("x")}}
+	if !reflect.DeepEqual(list, expected) {
+		t.Errorf("expected %v, got %v", expected, list)
+	}
+	list[0].Value = IntTagValue(2)
+	if v, _ := tags.GetValue("b"); v != IntTagValue(1) {
+		t.Errorf("expected List() to return a copy, got %v", v)
+	}
+}
+
+func TestStructuredError_Tags(t *testing.T) {
+	fe := NewRawStructuredError(nil)
+	_ = fe.AddTagInt("id", 1)
+	tags := fe.Tags()
+	tags.SetValue("extra", true)
+	if fe.tags.JsonValueString() != `{"id":1}` {
+		t.Errorf("expected Tags() to return a copy, got %s", fe.tags.JsonValueString())
+	}
+	if tags.JsonValueString() != `{"id":1,"extra":true}` {
+		t.Errorf("unexpected tags %s", tags.JsonValueString())
+	}
+}
@@ -0,0 +1,64 @@
+package serrors
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Template defines reusable errors of the same type and message format
+// Errors created by New() and Wrap() match the template by errors.Is(err, tmpl) regardless of formatted arguments.
+//
+//	var ErrUserNotFound = serrors.Define(NotFound, "user %d not found")
+//
+//	err := ErrUserNotFound.New(42)
+//	errors.Is(err, ErrUserNotFound) // true
+type Template struct {
+	errorType ErrorType
+	format    string
+	options   []WithFunc
+}
+
+// Define returns Template of type t
+// format is formatted by fmt.Sprintf with arguments of New() and Wrap().
+// options are applied to every error created by the template, e.g. WithTag(), WithPublicMessage().
+func Define(t ErrorType, format string, options ...WithFunc) *Template {
+	return &Template{
+		errorType: t,
+		format:    format,
+		options:   options,
+	}
+}
+
+// Error returns the type and the format not formatted
+// Template implements error only to be a target of errors.Is().
+func (tmpl *Template) Error() string {
+	return fmt.Sprintf("[Type: %s] %s", tmpl.errorType.StringWithDefaultNone(), tmpl.format)
+}
+
+func (tmpl *Template) Type() ErrorType {
+	return tmpl.errorType
+}
+
+// New creates StructuredError with message formatted by args and stack trace starting from caller of New
+func (tmpl *Template) New(args ...any) error {
+	return tmpl.build(errors.New(fmt.Sprintf(tmpl.format, args...)))
+}
+
+// Wrap creates StructuredError wrapping err with message formatted by args like Wrap()
+// stack trace starts from caller of Wrap even if err has stack trace.
+// if err is nil, it returns nil.
+func (tmpl *Template) Wrap(err error, args ...any) error {
+	if err == nil {
+		return nil
+	}
+	return tmpl.build(fmt.Errorf("%s: %w", fmt.Sprintf(tmpl.format, args...), err))
+}
+
+// build must be called directly from New or Wrap to start stack trace at their caller
+func (tmpl *Template) build(err error) error {
+	fe := NewRawStructuredError(err)
+	_ = fe.SetType(tmpl.errorType)
+	_ = fe.SetStackTraceWithSkipMaxDepth(3, MaxStackTraceDepth) // skip 3 to start at caller of New or Wrap
+	fe.template = tmpl
+	return With(fe, tmpl.options...)
+}
@@ -0,0 +1,125 @@
+package serrors
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+var (
+	testTemplateNotFound = Define("tmplNotFound", "user %d not found", WithTag("component", "user"), WithPublicMessage("user not found"))
+	testTemplateConflict = Define("tmplConflict", "conflict")
+)
+
+func TestTemplate_New(t *testing.T) {
+	err := testTemplateNotFound.New(42)
+
+	fe, ok := err.(*StructuredError)
+	if !ok {
+		t.Fatalf("expected *StructuredError, got %T", err)
+	}
+	if got := fe.Error(); got != "[Type: tmplNotFound] user 42 not found" {
+		t.Errorf("unexpected Error(): %s", got)
+	}
+	if got := fe.Type(); got != "tmplNotFound" {
+		t.Errorf("expected type tmplNotFound, got %s", got)
+	}
+	if v, ok := fe.tags.GetValue("component"); !ok || v != StringTagValue("user") {
+		t.Errorf("expected preset tag, got %v", v)
+	}
+	if got := fe.PublicMessage(); got != "user not found" {
+		t.Errorf("expected preset public message, got %s", got)
+	}
+	st := fe.StackTrace()
+	if len(st) == 0 || st[0].Function != "github.com/hinoguma/go-structured-error.TestTemplate_New" {
+		t.Errorf("expected stack trace to start at caller of New, got %v", st)
+	}
+}
+
+func TestTemplate_Wrap(t *testing.T) {
+	cause := errors.New("sql: no rows")
+	err := testTemplateNotFound.Wrap(cause, 7)
+
+	if got := err.Error(); got != "[Type: tmplNotFound] user 7 not found: sql: no rows" {
+		t.Errorf("unexpected Error(): %s", got)
+	}
+	if !errors.Is(err, cause) {
+		t.Errorf("expected wrapped error to match cause")
+	}
+	st := err.(*StructuredError).StackTrace()
+	if len(st) == 0 || st[0].Function != "github.com/hinoguma/go-structured-error.TestTemplate_Wrap" {
+		t.Errorf("expected stack trace to start at caller of Wrap, got %v", st)
+	}
+	if got := testTemplateNotFound.Wrap(nil, 7); got != nil {
+		t.Errorf("expected nil, got %v", got)
+	}
+}
+
+func TestTemplate_Is(t *testing.T) {
+	testCases := []struct {
+		label    string
+		err      error
+		target   error
+		expected bool
+	}{
+		{
+			label:    "created by New",
+			err:      testTemplateNotFound.New(1),
+			target:   testTemplateNotFound,
+			expected: true,
+		},
+		{
+			label:    "created by Wrap",
+			err:      testTemplateNotFound.Wrap(errors.New("cause"), 2),
+			target:   testTemplateNotFound,
+			expected: true,
+		},
+		{
+			label:    "wrapped by fmt.Errorf",
+			err:      fmt.Errorf("handler: %w", testTemplateNotFound.New(3)),
+			target:   testTemplateNotFound,
+			expected: true,
+		},
+		{
+			label:    "in sub errors",
+			err:      NewRawStructuredError(errors.New("main")).AddSubError(testTemplateNotFound.New(4)),
+			target:   testTemplateNotFound,
+			expected: true,
+		},
+		{
+			label:    "other template",
+			err:      testTemplateConflict.New(),
+			target:   testTemplateNotFound,
+			expected: false,
+		},
+		{
+			label:    "same type and message but not created by the template",
+			err:      With(errors.New("user 1 not found"), WithType("tmplNotFound")),
+			target:   testTemplateNotFound,
+			expected: false,
+		},
+		{
+			label:    "template defined again with the same type and format",
+			err:      Define("tmplNotFound", "user %d not found").New(1),
+			target:   testTemplateNotFound,
+			expected: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.label, func(t *testing.T) {
+			if got := errors.Is(tc.err, tc.target); got != tc.expected {
+				t.Errorf("expected %v, got %v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestTemplate_Error(t *testing.T) {
+	if got := testTemplateNotFound.Error(); got != "[Type: tmplNotFound] user %d not found" {
+		t.Errorf("unexpected Error(): %s", got)
+	}
+	if got := Define(ErrorTypeNone, "boom").Error(); got != "[Type: none] boom" {
+		t.Errorf("unexpected Error(): %s", got)
+	}
+}
@@ -0,0 +1,157 @@
+package serrors
+
+import (
+	"net/http"
+	"sync"
+)
+
+// SeverityLevel is the severity of the error type
+type SeverityLevel int
+
+const (
+	SeverityUnknown SeverityLevel = iota
+	SeverityDebug
+	SeverityInfo
+	SeverityWarning
+	SeverityError
+	SeverityCritical
+)
+
+func (s SeverityLevel) String() string {
+	switch s {
+	case SeverityDebug:
+		return "debug"
+	case SeverityInfo:
+		return "info"
+	case SeverityWarning:
+		return "warning"
+	case SeverityError:
+		return "error"
+	case SeverityCritical:
+		return "critical"
+	}
+	return "unknown"
+}
+
+// defaults returned by lookups if no registered type has the value
+const (
+	DefaultHTTPStatus int           = http.StatusInternalServerError
+	DefaultGRPCCode   int           = 2 // codes.Unknown
+	DefaultSeverity   SeverityLevel = SeverityError
+)
+
+// TypeInfo is the metadata of ErrorType registered by RegisterType()
+// zero values mean not set, and lookups fall back to inner errors or defaults.
+type TypeInfo struct {
+	// HTTPStatus is the HTTP status code returned to clients, e.g. http.StatusNotFound
+	HTTPStatus int
+	// GRPCCode is the number of google.golang.org/grpc/codes.Code, e.g. 5 for NotFound
+	GRPCCode int
+	Severity SeverityLevel
+	// Retryable reports whether the operation may succeed if retried
+	Retryable bool
+	// PublicMessage is used by PublicMessage() if the error has no public message
+	PublicMessage string
+	// DocURL is the URL of the documentation of the error type
+	DocURL string
+	// Parent is the parent type used by ErrorType.Parent() instead of the dot-separated prefix
+	Parent ErrorType
+}
+
+var typeRegistry = struct {
+	sync.RWMutex
+	types map[ErrorType]TypeInfo
+}{types: make(map[ErrorType]TypeInfo)}
+
+// RegisterType registers metadata of t
+// registering the same type again replaces the metadata.
+func RegisterType(t ErrorType, info TypeInfo) {
+	typeRegistry.Lock()
+	defer typeRegistry.Unlock()
+	typeRegistry.types[t] = info
+}
+
+// UnregisterType removes metadata of t
+func UnregisterType(t ErrorType) {
+	typeRegistry.Lock()
+	defer typeRegistry.Unlock()
+	delete(typeRegistry.types, t)
+}
+
+// LookupType returns metadata of t registered by RegisterType()
+func LookupType(t ErrorType) (TypeInfo, bool) {
+	typeRegistry.RLock()
+	defer typeRegistry.RUnlock()
+	info, ok := typeRegistry.types[t]
+	return info, ok
+}
+
+// HTTPStatus returns the HTTP status of the outermost error type having it in the chain of err
+// if err is nil, it returns http.StatusOK. if no type has it, it returns DefaultHTTPStatus.
+func HTTPStatus(err error) int {
+	if err == nil {
+		return http.StatusOK
+	}
+	info, ok := findTypeInfo(err, func(info TypeInfo) bool { return info.HTTPStatus != 0 })
+	if !ok {
+		return DefaultHTTPStatus
+	}
+	return info.HTTPStatus
+}
+
+// GRPCCode returns the gRPC code number of the outermost error type having it in the chain of err
+// if err is nil, it returns 0 (codes.OK). if no type has it, it returns DefaultGRPCCode.
+func GRPCCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	info, ok := findTypeInfo(err, func(info TypeInfo) bool { return info.GRPCCode != 0 })
+	if !ok {
+		return DefaultGRPCCode
+	}
+	return info.GRPCCode
+}
+
+// Severity returns the severity of the outermost error type having it in the chain of err
+// if err is nil, it returns SeverityUnknown. if no type has it, it returns DefaultSeverity.
+func Severity(err error) SeverityLevel {
+	if err == nil {
+		return SeverityUnknown
+	}
+	info, ok := findTypeInfo(err, func(info TypeInfo) bool { return info.Severity != SeverityUnknown })
+	if !ok {
+		return DefaultSeverity
+	}
+	return info.Severity
+}
+
+// IsRetryable reports whether the outermost registered error type in the chain of err is retryable
+func IsRetryable(err error) bool {
+	info, ok := findTypeInfo(err, func(info TypeInfo) bool { return true })
+	return ok && info.Retryable
+}
+
+// DocURL returns the documentation URL of the outermost error type having it in the chain of err
+func DocURL(err error) string {
+	info, _ := findTypeInfo(err, func(info TypeInfo) bool { return info.DocURL != "" })
+	return info.DocURL
+}
+
+// findTypeInfo walks the chain of err through HasType and returns metadata of the outermost registered type matching has
+// sub errors are not looked into.
+func findTypeInfo(err error, has func(TypeInfo) bool) (TypeInfo, bool) {
+	var found TypeInfo
+	ok := WalkChain(err, func(e error) bool {
+		ht, ok := e.(HasType)
+		if !ok {
+			return false
+		}
+		info, ok := LookupType(ht.Type())
+		if ok && has(info) {
+			found = info
+			return true
+		}
+		return false
+	})
+	return found, ok
+}
@@ -0,0 +1,204 @@
+package serrors
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func registerTestTypes(t *testing.T) {
+	t.Helper()
+	RegisterType("regNotFound", TypeInfo{
+		HTTPStatus:    http.StatusNotFound,
+		GRPCCode:      5,
+		Severity:      SeverityInfo,
+		PublicMessage: "resource not found",
+		DocURL:        "https://example.com/errors/not-found",
+	})
+	RegisterType("regUnavailable", TypeInfo{
+		HTTPStatus: http.StatusServiceUnavailable,
+		GRPCCode:   14,
+		Severity:   SeverityWarning,
+		Retryable:  true,
+	})
+	// registered with severity only
+	RegisterType("regCritical", TypeInfo{Severity: SeverityCritical})
+	t.Cleanup(func() {
+		UnregisterType("regNotFound")
+		UnregisterType("regUnavailable")
+		UnregisterType("regCritical")
+	})
+}
+
+func newTypedError(t ErrorType, message string) error {
+	fe := NewRawStructuredError(errors.New(message))
+	_ = fe.SetType(t)
+	return fe
+}
+
+func TestTypeRegistry_Lookups(t *testing.T) {
+	registerTestTypes(t)
+
+	testCases := []struct {
+		label      string
+		err        error
+		httpStatus int
+		grpcCode   int
+		severity   SeverityLevel
+		retryable  bool
+		docURL     string
+	}{
+		{
+			label:      "nil error",
+			err:        nil,
+			httpStatus: http.StatusOK,
+			grpcCode:   0,
+			severity:   SeverityUnknown,
+		},
+		{
+			label:      "standard error",
+			err:        errors.New("boom"),
+			httpStatus: DefaultHTTPStatus,
+			grpcCode:   DefaultGRPCCode,
+			severity:   DefaultSeverity,
+		},
+		{
+			label:      "unregistered type",
+			err:        newTypedError("regUnknown", "boom"),
+			httpStatus: DefaultHTTPStatus,
+			grpcCode:   DefaultGRPCCode,
+			severity:   DefaultSeverity,
+		},
+		{
+			label:      "registered type",
+			err:        newTypedError("regNotFound", "user not found"),
+			httpStatus: http.StatusNotFound,
+			grpcCode:   5,
+			severity:   SeverityInfo,
+			docURL:     "https://example.com/errors/not-found",
+		},
+		{
+			label:      "retryable type wrapped by fmt.Errorf",
+			err:        fmt.Errorf("call api: %w", newTypedError("regUnavailable", "unavailable")),
+			httpStatus: http.StatusServiceUnavailable,
+			grpcCode:   14,
+			severity:   SeverityWarning,
+			retryable:  true,
+		},
+		{
+			label:      "outermost type wins",
+			err:        NewRawStructuredError(newTypedError("regNotFound", "inner")).SetType("regUnavailable"),
+			httpStatus: http.StatusServiceUnavailable,
+			grpcCode:   14,
+			severity:   SeverityWarning,
+			retryable:  true,
+			docURL:     "https://example.com/errors/not-found",
+		},
+		{
+			label:      "values not set fall back to inner types",
+			err:        NewRawStructuredError(newTypedError("regNotFound", "inner")).SetType("regCritical"),
+			httpStatus: http.StatusNotFound,
+			grpcCode:   5,
+			severity:   SeverityCritical,
+			retryable:  false,
+			docURL:     "https://example.com/errors/not-found",
+		},
+		{
+			label:      "retryable of the outermost registered type is used",
+			err:        NewRawStructuredError(newTypedError("regUnavailable", "inner")).SetType("regCritical"),
+			httpStatus: http.StatusServiceUnavailable,
+			grpcCode:   14,
+			severity:   SeverityCritical,
+			retryable:  false,
+		},
+		{
+			label:      "joined errors",
+			err:        errors.Join(errors.New("plain"), newTypedError("regUnavailable", "unavailable")),
+			httpStatus: http.StatusServiceUnavailable,
+			grpcCode:   14,
+			severity:   SeverityWarning,
+			retryable:  true,
+		},
+		{
+			label: "sub errors are not looked into",
+			err: NewRawStructuredError(errors.New("main")).
+				AddSubError(newTypedError("regNotFound", "sub")),
+			httpStatus: DefaultHTTPStatus,
+			grpcCode:   DefaultGRPCCode,
+			severity:   DefaultSeverity,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.label, func(t *testing.T) {
+			if got := HTTPStatus(tc.err); got != tc.httpStatus {
+				t.Errorf("HTTPStatus() expected %d, got %d", tc.httpStatus, got)
+			}
+			if got := GRPCCode(tc.err); got != tc.grpcCode {
+				t.Errorf("GRPCCode() expected %d, got %d", tc.grpcCode, got)
+			}
+			if got := Severity(tc.err); got != tc.severity {
+				t.Errorf("Severity() expected %v, got %v", tc.severity, got)
+			}
+			if got := IsRetryable(tc.err); got != tc.retryable {
+				t.Errorf("IsRetryable() expected %v, got %v", tc.retryable, got)
+			}
+			if got := DocURL(tc.err); got != tc.docURL {
+				t.Errorf("DocURL() expected %q, got %q", tc.docURL, got)
+			}
+		})
+	}
+}
+
+func TestTypeRegistry_PublicMessage(t *testing.T) {
+	registerTestTypes(t)
+
+	if got := PublicMessage(newTypedError("regNotFound", "user 42")); got != "resource not found" {
+		t.Errorf("expected public message of TypeInfo, got %q", got)
+	}
+
+	SetDefaultPublicMessage("regNotFound", "not found")
+	defer SetDefaultPublicMessage("regNotFound", "")
+	if got := PublicMessage(newTypedError("regNotFound", "user 42")); got != "not found" {
+		t.Errorf("expected SetDefaultPublicMessage() to take precedence, got %q", got)
+	}
+}
+
+func TestRegisterType(t *testing.T) {
+	if _, ok := LookupType("regTemp"); ok {
+		t.Fatalf("expected regTemp not to be registered")
+	}
+	RegisterType("regTemp", TypeInfo{HTTPStatus: http.StatusBadRequest})
+	RegisterType("regTemp", TypeInfo{HTTPStatus: http.StatusConflict})
+	info, ok := LookupType("regTemp")
+	if !ok || info.HTTPStatus != http.StatusConflict {
+		t.Errorf("expected registered info to be replaced, got %+v", info)
+	}
+	UnregisterType("regTemp")
+	if _, ok := LookupType("regTemp"); ok {
+		t.Errorf("expected regTemp to be unregistered")
+	}
+}
+
+func TestSeverityLevel_String(t *testing.T) {
+	testCases := []struct {
+		severity SeverityLevel
+		expected string
+	}{
+		{severity: SeverityUnknown, expected: "unknown"},
+		{severity: SeverityDebug, expected: "debug"},
+		{severity: SeverityInfo, expected: "info"},
+		{severity: SeverityWarning, expected: "warning"},
+		{severity: SeverityError, expected: "error"},
+		{severity: SeverityCritical, expected: "critical"},
+		{severity: SeverityLevel(100), expected: "unknown"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.expected, func(t *testing.T) {
+			if got := tc.severity.String(); got != tc.expected {
+				t.Errorf("expected %s, got %s", tc.expected, got)
+			}
+		})
+	}
+}
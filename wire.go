@@ -0,0 +1,238 @@
+package serrors
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// WireVersion is the version of the wire encoding written by EncodeWire
+const WireVersion int = 1
+
+// MaxWireDepth is the maximum length of chains and nesting of sub errors in the wire encoding
+// deeper errors are dropped by EncodeWire and rejected by DecodeWire.
+const MaxWireDepth int = 32
+
+// UnknownOriginStr is the origin of errors decoded from the wire encoding without origin
+const UnknownOriginStr string = "unknown"
+
+// WireOptions configures EncodeWire
+type WireOptions struct {
+	// Origin is the name of the service encoding the error
+	Origin string
+	// StackTrace includes stack traces. they can make the encoding large.
+	StackTrace bool
+}
+
+// wireEnvelope is the top level object of the wire encoding
+type wireEnvelope struct {
+	Version int        `json:"v"`
+	Origin  string     `json:"origin,omitempty"`
+	Chain   []wireLink `json:"chain"`
+}
+
+// wireLink is an error in the chain from the outermost to the innermost
+// plain links are errors which are not SError, kept only if they are the outermost or the innermost.
+type wireLink struct {
+	Plain         bool         `json:"plain,omitempty"`
+	Type          string       `json:"type,omitempty"`
+	Message       *string      `json:"message,omitempty"`
+	When          *time.Time   `json:"when,omitempty"`
+	RequestID     string       `json:"request_id,omitempty"`
+	PublicMessage string       `json:"public_message,omitempty"`
+	Origin        string       `json:"origin,omitempty"`
+	Tags          *Tags        `json:"tags,omitempty"`
+	StackTrace    StackTrace   `json:"stacktrace,omitempty"`
+	SubErrors     [][]wireLink `json:"sub_errors,omitempty"`
+}
+
+// wireMessageError restores the message of a link wrapping the next link
+type wireMessageError struct {
+	message string
+	err     error
+}
+
+func (e *wireMessageError) Error() string {
+	return e.message
+}
+
+func (e *wireMessageError) Unwrap() error {
+	return e.err
+}
+
+// EncodeWire encodes err into compact JSON to propagate it to other services
+// It keeps types, messages, when, request ids, public messages, tags and sub errors of every SError in the chain.
+// tags are redacted by DefaultRedactor() and messages are scrubbed by DefaultScrubber() like printers.
+// if err is nil, it returns nil. if opts is nil, default options are used.
+func EncodeWire(err error, opts *WireOptions) []byte {
+	if err == nil {
+		return nil
+	}
+	o := WireOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	envelope := wireEnvelope{
+		Version: WireVersion,
+		Origin:  o.Origin,
+		Chain:   encodeWireChain(err, o, 1),
+	}
+	data, marshalErr := json.Marshal(envelope)
+	if marshalErr != nil {
+		// tags which cannot be marshaled are dropped
+		for i := range envelope.Chain {
+			envelope.Chain[i].Tags = nil
+		}
+		data, _ = json.Marshal(envelope)
+	}
+	return data
+}
+
+func encodeWireChain(err error, o WireOptions, depth int) []wireLink {
+	links := make([]wireLink, 0)
+	scrubber := DefaultScrubber()
+	for err != nil && len(links) < MaxWireDepth {
+		fe, ok := err.(SError)
+		if !ok {
+			next := errors.Unwrap(err)
+			if len(links) == 0 || next == nil {
+				message := scrubMessage(err.Error(), scrubber)
+				links = append(links, wireLink{Plain: true, Message: &message})
+			}
+			err = next
+			continue
+		}
+
+		link := wireLink{
+			Type:      string(fe.Type()),
+			When:      fe.When(),
+			RequestID: fe.RequestID(),
+		}
+		if inner := fe.Unwrap(); inner != nil {
+			message := scrubMessage(inner.Error(), scrubber)
+			link.Message = &message
+		}
+		if pm, ok := fe.(HasPublicMessage); ok {
+			link.PublicMessage = pm.PublicMessage()
+		}
+		if oe, ok := fe.(interface{ Origin() string }); ok {
+			link.Origin = oe.Origin()
+		}
+		if te, ok := fe.(interface{ Tags() Tags }); ok {
+			if tags := redactTags(te.Tags(), DefaultRedactor()); len(tags.tags) > 0 {
+				link.Tags = &tags
+			}
+		}
+		if o.StackTrace {
+			link.StackTrace = fe.StackTrace()
+		}
+		if se, ok := fe.(HasSubErrors); ok && depth < MaxWireDepth {
+			for _, subErr := range se.SubErrors() {
+				if subErr != nil {
+					link.SubErrors = append(link.SubErrors, encodeWireChain(subErr, o, depth+1))
+				}
+			}
+		}
+		links = append(links, link)
+		err = fe.Unwrap()
+	}
+	return links
+}
+
+// DecodeWire decodes the wire encoding made by EncodeWire
+// Every SError in the restored chain is marked as remote with the origin of the encoding,
+// and types are kept, so IsType() works across services. See IsRemote() and RemoteOrigin().
+func DecodeWire(data []byte) (SError, error) {
+	var envelope wireEnvelope
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return nil, fmt.Errorf("serrors: invalid wire encoding: %w", err)
+	}
+	if envelope.Version != WireVersion {
+		return nil, fmt.Errorf("serrors: unsupported wire version: %d", envelope.Version)
+	}
+	origin := envelope.Origin
+	if origin == "" {
+		origin = UnknownOriginStr
+	}
+	err, decodeErr := decodeWireChain(envelope.Chain, origin, 1)
+	if decodeErr != nil {
+		return nil, decodeErr
+	}
+	if err == nil {
+		return nil, errors.New("serrors: empty wire encoding")
+	}
+	fe, ok := err.(SError)
+	if !ok {
+		restored := NewRawStructuredError(err)
+		restored.origin = origin
+		return restored, nil
+	}
+	return fe, nil
+}
+
+func decodeWireChain(links []wireLink, origin string, depth int) (error, error) {
+	if len(links) > MaxWireDepth || depth > MaxWireDepth {
+		return nil, errors.New("serrors: wire encoding is too deep")
+	}
+	var next error
+	for i := len(links) - 1; i >= 0; i-- {
+		link := links[i]
+		if link.Plain {
+			message := ""
+			if link.Message != nil {
+				message = *link.Message
+			}
+			next = &wireMessageError{message: message, err: next}
+			continue
+		}
+
+		inner := next
+		if link.Message != nil {
+			inner = &wireMessageError{message: *link.Message, err: next}
+		}
+		fe := NewRawStructuredError(inner)
+		fe.errorType = ErrorType(link.Type)
+		fe.when = link.When
+		fe.requestId = link.RequestID
+		fe.publicMessage = link.PublicMessage
+		fe.origin = link.Origin
+		if fe.origin == "" {
+			fe.origin = origin
+		}
+		if link.Tags != nil {
+			fe.tags = *link.Tags
+		}
+		if link.StackTrace != nil {
+			fe.stacktrace = link.StackTrace
+		}
+		for _, subLinks := range link.SubErrors {
+			subErr, err := decodeWireChain(subLinks, origin, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			_ = fe.AddSubError(subErr)
+		}
+		next = fe
+	}
+	return next, nil
+}
+
+// IsRemote reports whether err or any of its wrapped errors is decoded by DecodeWire()
+func IsRemote(err error) bool {
+	return RemoteOrigin(err) != ""
+}
+
+// RemoteOrigin returns the origin of the outermost remote error in the chain of err
+// it is UnknownOriginStr if the encoding has no origin, and empty if err is not remote.
+func RemoteOrigin(err error) string {
+	origin := ""
+	WalkChain(err, func(e error) bool {
+		if oe, ok := e.(interface{ Origin() string }); ok && oe.Origin() != "" {
+			origin = oe.Origin()
+			return true
+		}
+		return false
+	})
+	return origin
+}
@@ -0,0 +1,144 @@
+package serrors
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestWireError() error {
+	tm := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	root := errors.New("sql: no rows")
+	inner := Builder(fmt.Errorf("select user: %w", root)).
+		Type("db.notFound").
+		AddTagString("table", "users").
+		Build()
+	sub := With(errors.New("cache miss"), WithType("cache"))
+	outer := Builder(fmt.Errorf("load user: %w", inner)).
+		Type("user.notFound").
+		When(tm).
+		RequestID("req-1").
+		PublicMessage("user not found").
+		AddTagInt("user_id", 42).
+		AddTagSensitive("email", "alice@example.com").
+		Build()
+	_ = outer.(SError).AddSubError(sub)
+	return fmt.Errorf("handler: %w", outer)
+}
+
+func TestEncodeWire_RoundTrip(t *testing.T) {
+	original := newTestWireError()
+	data := EncodeWire(original, &WireOptions{Origin: "user-service"})
+
+	decoded, err := DecodeWire(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// the plain outermost error is restored wrapped by StructuredError like ToStructured()
+	if decoded.Error() != ToStructured(original).Error() {
+		t.Errorf("expected message %s, got %s", ToStructured(original).Error(), decoded.Error())
+	}
+	for _, tp := range []ErrorType{"user.notFound", "db.notFound", "cache"} {
+		if !IsType(decoded, tp) {
+			t.Errorf("expected IsType(decoded, %s) to be true", tp)
+		}
+	}
+	if !IsTypeOrChild(decoded, "db") {
+		t.Errorf("expected IsTypeOrChild(decoded, db) to be true")
+	}
+	if !IsRemote(decoded) || RemoteOrigin(decoded) != "user-service" {
+		t.Errorf("expected remote error of user-service, got %q", RemoteOrigin(decoded))
+	}
+	if PublicMessage(decoded) != "user not found" {
+		t.Errorf("expected public message, got %s", PublicMessage(decoded))
+	}
+
+	var outer *StructuredError
+	if !errors.As(decoded, &outer) {
+		t.Fatalf("expected *StructuredError in chain")
+	}
+	// decoded starts from the plain outermost link
+	if !errors.As(errors.Unwrap(errors.Unwrap(decoded)), &outer) || outer.Type() != "user.notFound" {
+		t.Fatalf("expected user.notFound error, got %v", outer)
+	}
+	if outer.RequestID() != "req-1" || outer.When() == nil || !outer.When().Equal(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected request id or when %s %v", outer.RequestID(), outer.When())
+	}
+	expectedTags := `{"user_id":42,"email":"[REDACTED]"}`
+	if outer.tags.JsonValueString() != expectedTags {
+		t.Errorf("expected tags %s, got %s", expectedTags, outer.tags.JsonValueString())
+	}
+	if len(outer.SubErrors()) != 1 || !IsRemote(outer.SubErrors()[0]) {
+		t.Errorf("expected remote sub error, got %v", outer.SubErrors())
+	}
+	if len(outer.StackTrace()) != 0 {
+		t.Errorf("expected no stack trace without WireOptions.StackTrace")
+	}
+	if !strings.Contains(outer.JsonString(), `"origin":"user-service"`) {
+		t.Errorf("expected origin in JSON, got %s", outer.JsonString())
+	}
+}
+
+func TestEncodeWire_StackTrace(t *testing.T) {
+	data := EncodeWire(New("boom"), &WireOptions{StackTrace: true})
+	decoded, err := DecodeWire(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	st := decoded.StackTrace()
+	if len(st) == 0 || st[0].Function != "github.com/hinoguma/go-structured-error.TestEncodeWire_StackTrace" {
+		t.Errorf("expected stack trace to be restored, got %v", st)
+	}
+	if RemoteOrigin(decoded) != UnknownOriginStr {
+		t.Errorf("expected unknown origin, got %s", RemoteOrigin(decoded))
+	}
+}
+
+func TestEncodeWire_KeepsOriginOfRemoteErrors(t *testing.T) {
+	fromB, err := DecodeWire(EncodeWire(With(errors.New("b failed"), WithType("b")), &WireOptions{Origin: "service-b"}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	inA := Builder(fmt.Errorf("call b: %w", fromB)).Type("a").Build()
+
+	decoded, err := DecodeWire(EncodeWire(inA, &WireOptions{Origin: "service-a"}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if RemoteOrigin(decoded) != "service-a" {
+		t.Errorf("expected service-a, got %s", RemoteOrigin(decoded))
+	}
+	var b *StructuredError
+	if !errors.As(decoded.Unwrap(), &b) || b.Type() != "b" || b.Origin() != "service-b" {
+		t.Errorf("expected origin of b to be kept, got %v", b)
+	}
+}
+
+func TestEncodeWire_Nil(t *testing.T) {
+	if EncodeWire(nil, nil) != nil {
+		t.Errorf("expected nil")
+	}
+}
+
+func TestDecodeWire_Invalid(t *testing.T) {
+	deep := `{"v":1,"chain":[` + strings.Repeat(`{"type":"a"},`, MaxWireDepth) + `{"type":"a"}]}`
+	testCases := []struct {
+		label string
+		data  string
+	}{
+		{label: "not JSON", data: `not json`},
+		{label: "unsupported version", data: `{"v":2,"chain":[{"type":"a"}]}`},
+		{label: "empty chain", data: `{"v":1,"chain":[]}`},
+		{label: "too deep", data: deep},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.label, func(t *testing.T) {
+			if _, err := DecodeWire([]byte(tc.data)); err == nil {
+				t.Errorf("expected error")
+			}
+		})
+	}
+}
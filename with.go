@@ -49,6 +49,18 @@ func WithType(t ErrorType) WithFunc {
 	}
 }
 
+// WithPublicMessage sets the message safe to show to users
+func WithPublicMessage(message string) WithFunc {
+	return func(err error) error {
+		fe := ToStructuredError(err)
+		if fe == nil {
+			return nil
+		}
+		_ = fe.SetPublicMessage(message)
+		return fe
+	}
+}
+
 func WithTagSafe(key string, value TagValue) WithFunc {
 	return func(err error) error {
 		fe := ToStructuredError(err)
@@ -59,3 +71,21 @@ func WithTagSafe(key string, value TagValue) WithFunc {
 		return fe
 	}
 }
+
+func WithTagTime(key string, value time.Time) WithFunc {
+	return WithTagSafe(key, TimeTagValue{Time: value})
+}
+
+func WithTagDuration(key string, value time.Duration) WithFunc {
+	return WithTagSafe(key, DurationTagValue(value))
+}
+
+// WithTag converts value into TagValue by ToTagValue() and adds it
+func WithTag(key string, value any) WithFunc {
+	return WithTagSafe(key, ToTagValue(value))
+}
+
+// WithTagSensitive adds value as SecretTagValue, which is printed as RedactedStr
+func WithTagSensitive(key string, value any) WithFunc {
+	return WithTagSafe(key, SecretTagValue{Value: ToTagValue(value)})
+}
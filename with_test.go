@@ -3,6 +3,7 @@ package serrors
 import (
 	"errors"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 )
@@ -412,3 +413,65 @@ func TestWithTagSafe(t *testing.T) {
 		})
 	}
 }
+
+func TestWithTag(t *testing.T) {
+	testCases := []struct {
+		label  string
+		err    error
+		key    string
+		value  any
+		expect error
+	}{
+		{
+			label:  "duration",
+			err:    errors.New("some error"),
+			key:    "timeout",
+			value:  2 * time.Second,
+			expect: NewRawStructuredError(errors.New("some error")).AddTagSafe("timeout", DurationTagValue(2*time.Second)),
+		},
+		{
+			label:  "nil pointer",
+			err:    errors.New("some error"),
+			key:    "user",
+			value:  (*int)(nil),
+			expect: NewRawStructuredError(errors.New("some error")).AddTagSafe("user", NilTagValue{}),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.label, func(t *testing.T) {
+			wrapped := WithTag(tc.key, tc.value)(tc.err)
+			if !reflect.DeepEqual(wrapped, tc.expect) {
+				t.Errorf("WithTag() = %+v, want %+v", wrapped, tc.expect)
+			}
+		})
+	}
+}
+
+func TestWithTagTime(t *testing.T) {
+	tm := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	wrapped := WithTagTime("deadline", tm)(errors.New("some error"))
+	expected := NewRawStructuredError(errors.New("some error")).AddTagSafe("deadline", TimeTagValue{Time: tm})
+	if !reflect.DeepEqual(wrapped, expected) {
+		t.Errorf("WithTagTime() = %+v, want %+v", wrapped, expected)
+	}
+}
+
+func TestWithTagDuration(t *testing.T) {
+	wrapped := WithTagDuration("timeout", time.Second)(errors.New("some error"))
+	expected := NewRawStructuredError(errors.New("some error")).AddTagSafe("timeout", DurationTagValue(time.Second))
+	if !reflect.DeepEqual(wrapped, expected) {
+		t.Errorf("WithTagDuration() = %+v, want %+v", wrapped, expected)
+	}
+}
+
+func TestWithTagSensitive(t *testing.T) {
+	wrapped := WithTagSensitive("token", "abc")(errors.New("some error"))
+	expected := NewRawStructuredError(errors.New("some error")).AddTagSafe("token", SecretTagValue{Value: StringTagValue("abc")})
+	if !reflect.DeepEqual(wrapped, expected) {
+		t.Errorf("WithTagSensitive() = %+v, want %+v", wrapped, expected)
+	}
+	if got := ToJsonString(wrapped); strings.Contains(got, "abc") {
+		t.Errorf("expected secret not to be printed, got %s", got)
+	}
+}